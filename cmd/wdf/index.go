@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Jason-0902/wdf/internal/scanner"
+)
+
+// indexOptions holds the --index-* and per-engine flags.
+type indexOptions struct {
+	mode      string
+	cachePath string
+	cacheTTL  time.Duration
+
+	googleAPIKey   string
+	googleCX       string
+	googleEndpoint string
+	googlePages    int
+
+	bingKey      string
+	bingMarket   string
+	bingEndpoint string
+	bingRate     float64
+
+	importFiles stringList
+}
+
+// newIndexChecker returns a nil checker when none is selected, and the cache to save after the scan.
+func newIndexChecker(names string, o indexOptions, timeout time.Duration) (scanner.IndexChecker, *scanner.CachedIndexChecker, error) {
+	var checkers []scanner.IndexChecker
+	var used []string
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "", "none":
+			continue
+		case "google":
+			if o.googleAPIKey == "" {
+				o.googleAPIKey = os.Getenv("WDF_GOOGLE_API_KEY")
+			}
+			if o.googleCX == "" {
+				o.googleCX = os.Getenv("WDF_GOOGLE_CX")
+			}
+			if o.googleAPIKey == "" || o.googleCX == "" {
+				return nil, nil, errors.New("--index-checker google requires --google-api-key and --google-cx (or WDF_GOOGLE_API_KEY and WDF_GOOGLE_CX)")
+			}
+			if o.googlePages <= 0 || o.googlePages > 10 {
+				return nil, nil, errors.New("--google-max-pages must be between 1 and 10")
+			}
+			g := scanner.NewGoogleIndexChecker(o.googleAPIKey, o.googleCX)
+			g.BaseURL = o.googleEndpoint
+			g.MaxPages = o.googlePages
+			g.Client.Timeout = timeout
+			checkers = append(checkers, g)
+		case "bing":
+			if o.bingKey == "" {
+				o.bingKey = os.Getenv("WDF_BING_KEY")
+			}
+			if o.bingKey == "" {
+				return nil, nil, errors.New("--index-checker bing requires --bing-key (or WDF_BING_KEY)")
+			}
+			if o.bingRate <= 0 {
+				return nil, nil, errors.New("--bing-rate must be > 0")
+			}
+			b := scanner.NewBingIndexChecker(o.bingKey, o.bingMarket)
+			b.Endpoint = o.bingEndpoint
+			b.MinInterval = time.Duration(float64(time.Second) / o.bingRate)
+			b.Client.Timeout = timeout
+			checkers = append(checkers, b)
+		case "import":
+			if len(o.importFiles) == 0 {
+				return nil, nil, errors.New("--index-checker import requires --index-import")
+			}
+			ic, err := scanner.LoadOfflineIndex(o.importFiles...)
+			if err != nil {
+				return nil, nil, err
+			}
+			checkers = append(checkers, ic)
+		default:
+			return nil, nil, fmt.Errorf("--index-checker: unknown checker %q (want google, bing, import)", name)
+		}
+		used = append(used, name)
+	}
+	if o.mode != "any" && o.mode != "all" {
+		return nil, nil, errors.New("--index-mode must be any or all")
+	}
+	if o.cacheTTL < 0 {
+		return nil, nil, errors.New("--index-cache-ttl must be >= 0")
+	}
+
+	var checker scanner.IndexChecker
+	switch len(checkers) {
+	case 0:
+		return nil, nil, nil
+	case 1:
+		checker = checkers[0]
+	default:
+		checker = &scanner.CompositeIndexChecker{Checkers: checkers, All: o.mode == "all"}
+	}
+	if o.cachePath == "" {
+		return checker, nil, nil
+	}
+
+	if len(used) == 1 && used[0] == "import" {
+		// Imported answers are already local; caching them would only let them go stale.
+		return checker, nil, nil
+	}
+	namespace := strings.Join(used, ",")
+	if len(used) > 1 {
+		namespace = o.mode + ":" + namespace
+	}
+	cache, err := scanner.NewCachedIndexChecker(checker, o.cachePath, o.cacheTTL, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache, cache, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -38,6 +39,22 @@ func run(args []string, stdout, stderr io.Writer) int {
 		enableCrawl   bool
 		crawlDepth    int
 		crawlLimit    int
+		crawlWorkers  int
+		crawlRobots   bool
+		crawlInclude  regexpList
+		crawlExclude  regexpList
+		errorProbes   bool
+		jsEndpoints   bool
+		jsMaxPaths    int
+
+		archiveBudget int64
+		followUpLimit int
+		followUpDepth int
+		followListing bool
+		probeAPIs     bool
+
+		indexChecker string
+		indexOpts    indexOptions
 
 		showVersion bool
 		showHelp    bool
@@ -55,8 +72,35 @@ func run(args []string, stdout, stderr io.Writer) int {
 	fs.BoolVar(&enableRobots, "enable-robots", false, "enable robots.txt discovery (disabled by default)")
 	fs.BoolVar(&enableSitemap, "enable-sitemap", false, "enable sitemap.xml discovery (disabled by default)")
 	fs.BoolVar(&enableCrawl, "enable-crawl", false, "enable lightweight same-origin HTML discovery (disabled by default)")
-	fs.IntVar(&crawlDepth, "crawl-depth", 2, "crawler depth (max 2)")
+	fs.IntVar(&crawlDepth, "crawl-depth", 2, "crawler depth (max 10)")
 	fs.IntVar(&crawlLimit, "crawl-limit", 20, "max pages fetched per target during crawling")
+	fs.IntVar(&crawlWorkers, "crawl-workers", 4, "max concurrent crawler fetches per target")
+	fs.BoolVar(&crawlRobots, "crawl-respect-robots", false, "skip pages disallowed by robots.txt for the wdf user-agent and honour its Crawl-delay")
+	fs.Var(&crawlInclude, "crawl-include", "only crawl paths matching this regexp (repeatable)")
+	fs.Var(&crawlExclude, "crawl-exclude", "never crawl paths matching this regexp (repeatable)")
+	fs.BoolVar(&jsEndpoints, "enable-js-endpoints", false, "extract API paths and routes from crawled HTML and JavaScript (uses the start page when crawling is off)")
+	fs.IntVar(&jsMaxPaths, "js-max-paths", 200, "max new paths JS/HTML endpoint extraction adds per target")
+	fs.BoolVar(&errorProbes, "enable-error-probes", false, "request a random missing path and a malformed extension per target to detect debug/error pages")
+
+	fs.Int64Var(&archiveBudget, "archive-budget", 1<<20, "max bytes read per exposed ZIP archive when listing its contents")
+	fs.IntVar(&followUpLimit, "follow-up-limit", 200, "max extra paths per target discovered from scanned content (e.g. .DS_Store listings)")
+	fs.IntVar(&followUpDepth, "follow-up-depth", 3, "max discovery hops for paths found in scanned content")
+	fs.BoolVar(&probeAPIs, "probe-apis", false, "send unauthenticated GETs to parameterless GET operations of exposed OpenAPI documents")
+	fs.BoolVar(&followListing, "follow-listings", false, "scan files and subdirectories found in directory listings (bounded by --follow-up-limit/--follow-up-depth)")
+
+	fs.StringVar(&indexChecker, "index-checker", "", "comma-separated index checkers to run on interesting findings: google, bing, import")
+	fs.StringVar(&indexOpts.mode, "index-mode", "any", "with several index checkers, whether any or all must find a URL: any, all")
+	fs.StringVar(&indexOpts.cachePath, "index-cache", "", "keep index check answers in this JSON file across runs")
+	fs.DurationVar(&indexOpts.cacheTTL, "index-cache-ttl", 7*24*time.Hour, "age after which cached index check answers are checked again")
+	fs.Var(&indexOpts.importFiles, "index-import", "search console export (CSV/TSV, JSON) or URL list of indexed pages for --index-checker import (repeatable)")
+	fs.StringVar(&indexOpts.googleAPIKey, "google-api-key", "", "Custom Search JSON API key (default: $WDF_GOOGLE_API_KEY)")
+	fs.StringVar(&indexOpts.googleCX, "google-cx", "", "Programmable Search Engine ID (default: $WDF_GOOGLE_CX)")
+	fs.StringVar(&indexOpts.googleEndpoint, "google-endpoint", scanner.GoogleCSEEndpoint, "Custom Search JSON API endpoint")
+	fs.IntVar(&indexOpts.googlePages, "google-max-pages", 3, "max result pages (10 results each) read per Google query")
+	fs.StringVar(&indexOpts.bingKey, "bing-key", "", "Bing Web Search API subscription key (default: $WDF_BING_KEY)")
+	fs.StringVar(&indexOpts.bingMarket, "bing-market", "en-US", "Bing results market")
+	fs.StringVar(&indexOpts.bingEndpoint, "bing-endpoint", scanner.BingSearchEndpoint, "Bing Web Search API endpoint")
+	fs.Float64Var(&indexOpts.bingRate, "bing-rate", 3, "max Bing requests per second")
 
 	fs.BoolVar(&showVersion, "version", false, "print version and exit")
 	fs.BoolVar(&showHelp, "h", false, "show help")
@@ -119,6 +163,32 @@ func run(args []string, stdout, stderr io.Writer) int {
 		fmt.Fprintln(stderr, "error: --crawl-limit must be >= 0")
 		return 2
 	}
+	if jsMaxPaths < 0 {
+		fmt.Fprintln(stderr, "error: --js-max-paths must be >= 0")
+		return 2
+	}
+	if crawlWorkers <= 0 {
+		fmt.Fprintln(stderr, "error: --crawl-workers must be > 0")
+		return 2
+	}
+	if archiveBudget <= 0 {
+		fmt.Fprintln(stderr, "error: --archive-budget must be > 0")
+		return 2
+	}
+	if followUpLimit <= 0 {
+		fmt.Fprintln(stderr, "error: --follow-up-limit must be > 0")
+		return 2
+	}
+	if followUpDepth <= 0 {
+		fmt.Fprintln(stderr, "error: --follow-up-depth must be > 0")
+		return 2
+	}
+
+	checker, cache, err := newIndexChecker(indexChecker, indexOpts, time.Duration(timeoutSec)*time.Second)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 2
+	}
 
 	targets, err := loadTargets(targetURL, listPath)
 	if err != nil {
@@ -148,8 +218,25 @@ func run(args []string, stdout, stderr io.Writer) int {
 		EnableRobots:  enableRobots,
 		EnableSitemap: enableSitemap,
 		EnableCrawl:   enableCrawl,
-		CrawlDepth:    clampInt(crawlDepth, 0, 2),
+		CrawlDepth:    clampInt(crawlDepth, 0, 10),
 		CrawlLimit:    crawlLimit,
+
+		CrawlWorkers:       crawlWorkers,
+		CrawlRespectRobots: crawlRobots,
+		CrawlInclude:       crawlInclude,
+		CrawlExclude:       crawlExclude,
+
+		EnableJSEndpoints: jsEndpoints,
+		JSMaxPaths:        jsMaxPaths,
+		EnableErrorProbes: errorProbes,
+
+		ArchiveByteBudget: archiveBudget,
+		FollowUpLimit:     followUpLimit,
+		FollowUpDepth:     followUpDepth,
+		FollowListings:    followListing,
+		ProbeAPIs:         probeAPIs,
+
+		IndexChecker: checker,
 	}
 
 	ctx := context.Background()
@@ -160,6 +247,11 @@ func run(args []string, stdout, stderr io.Writer) int {
 
 	rs := scanner.DefaultRuleSet()
 	rep.Targets = scanner.ScanTargets(ctx, targets, cfg, rs)
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			fmt.Fprintln(stderr, "warning: saving index cache:", err)
+		}
+	}
 
 	var f *os.File
 	if output != "" {
@@ -190,6 +282,38 @@ func run(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
+// regexpList is a repeatable flag of regular expressions, compiled as they are parsed.
+type regexpList []*regexp.Regexp
+
+func (l *regexpList) String() string {
+	parts := make([]string, 0, len(*l))
+	for _, re := range *l {
+		parts = append(parts, re.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *regexpList) Set(v string) error {
+	re, err := regexp.Compile(v)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, re)
+	return nil
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
 func clampInt(v, min, max int) int {
 	if v < min {
 		return min
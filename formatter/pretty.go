@@ -77,6 +77,76 @@ func printSummary(w io.Writer, t scanner.TargetResult, findings []scanner.Reques
 	fmt.Fprintf(w, "  Low: %d\n", low)
 	fmt.Fprintf(w, "  Total Findings: %d\n", len(findings))
 	fmt.Fprintf(w, "  Scan Duration: %s\n", fmtDuration(dur))
+	if d := t.Discovery; d != nil {
+		sources := make([]string, 0, len(d.PathsBySource))
+		for s, n := range d.PathsBySource {
+			sources = append(sources, fmt.Sprintf("%s %d", s, n))
+		}
+		sort.Strings(sources)
+		fmt.Fprintf(w, "  Discovery: %s\n", strings.Join(sources, ", "))
+		if d.JSPathsDropped > 0 {
+			fmt.Fprintf(w, "    js: %d paths added, %d dropped by --js-max-paths\n", d.JSPaths, d.JSPathsDropped)
+		}
+		for _, e := range d.Errors {
+			fmt.Fprintf(w, "    error: %s\n", e)
+		}
+	}
+	if c := t.Crawl; c != nil {
+		fmt.Fprintf(w, "  Crawl: %d pages fetched, %d URLs discovered, %d skipped, %d errors\n", c.Fetched, c.Discovered, c.Skipped, c.Errors)
+	}
+	if r := t.Robots; r != nil {
+		rules := 0
+		for _, g := range r.Groups {
+			rules += len(g.Allow) + len(g.Disallow)
+		}
+		fmt.Fprintf(w, "  Robots: %d groups, %d rules, %d sitemaps\n", len(r.Groups), rules, len(r.Sitemaps))
+	}
+	if l := t.RobotsLeak; l != nil {
+		fmt.Fprintf(w, "  Robots leak: %d sensitive entries, %d reachable\n", len(l.Entries), l.Reachable)
+		for _, e := range l.Entries {
+			if !e.Reachable {
+				continue
+			}
+			fmt.Fprintf(w, "    %s %s (%s)\n", e.Directive, e.Pattern, strings.Join(e.Keywords, ", "))
+		}
+	}
+	if len(t.GraphQL) > 0 {
+		introspection := 0
+		for _, g := range t.GraphQL {
+			if g.Introspection {
+				introspection++
+			}
+		}
+		fmt.Fprintf(w, "  GraphQL: %d endpoints, %d with introspection enabled\n", len(t.GraphQL), introspection)
+		for _, g := range t.GraphQL {
+			if !g.Introspection {
+				continue
+			}
+			fmt.Fprintf(w, "    %s (%d types, %d mutations)\n", g.Endpoint, g.Types, len(g.Mutations))
+		}
+	}
+	if s := t.Sitemap; s != nil {
+		fmt.Fprintf(w, "  Sitemaps: %d URLs in %d files, %d flagged\n", s.URLs, len(s.Files), len(s.Flagged))
+		for _, f := range s.Flagged {
+			if f.Severity == scanner.SeverityLow {
+				continue
+			}
+			fmt.Fprintf(w, "    %-6s %s (%s)\n", strings.ToUpper(string(f.Severity)), f.URL, strings.Join(f.Reasons, "; "))
+		}
+	}
+	if len(t.Parameters) > 0 {
+		var fileLike []string
+		for _, p := range t.Parameters {
+			if p.FileLike {
+				fileLike = append(fileLike, p.Name)
+			}
+		}
+		fmt.Fprintf(w, "  Parameters: %d", len(t.Parameters))
+		if len(fileLike) > 0 {
+			fmt.Fprintf(w, " (file/path candidates: %s)", strings.Join(fileLike, ", "))
+		}
+		fmt.Fprintln(w)
+	}
 }
 
 func filterFindings(results []scanner.RequestResult) []scanner.RequestResult {
@@ -116,7 +186,19 @@ func printGroup(w io.Writer, useColor bool, sev scanner.Severity, results []scan
 		if tag := discoveryTag(r.DiscoverySource); tag != "" {
 			note = strings.TrimSpace(note + " " + tag)
 		}
+		if r.SitemapLastMod != "" {
+			note += " (sitemap lastmod " + r.SitemapLastMod + ")"
+		}
+		if r.Indexability != nil && r.Analysis.Interesting {
+			note += " [index: " + string(r.Indexability.Verdict) + "]"
+		}
 		fmt.Fprintf(w, "  %-*s %-5d %s\n", pathW, r.Path, r.StatusCode, note)
+		for _, line := range evidenceLines(r.Evidence) {
+			fmt.Fprintf(w, "  %-*s       %s\n", pathW, "", line)
+		}
+		if e := r.IndexCheckError; e != nil {
+			fmt.Fprintf(w, "  %-*s       index check failed: %s\n", pathW, "", e.Message)
+		}
 	}
 	fmt.Fprintln(w)
 }
@@ -153,6 +235,229 @@ func humanizeReason(path string, status int, reason string) string {
 	return reason
 }
 
+// evidenceLines summarises structured evidence as short indented lines under a finding.
+func evidenceLines(ev *scanner.Evidence) []string {
+	if ev == nil {
+		return nil
+	}
+	var out []string
+	if a := ev.Archive; a != nil {
+		switch {
+		case a.Error != "":
+			out = append(out, "archive: "+a.Error)
+		default:
+			line := fmt.Sprintf("archive: %d entries", a.TotalEntries)
+			if len(a.SensitiveEntries) > 0 {
+				line += fmt.Sprintf(", %d sensitive (%s)", len(a.SensitiveEntries), strings.Join(firstN(a.SensitiveEntries, 3), ", "))
+			}
+			if a.Truncated {
+				line += ", listing truncated"
+			}
+			out = append(out, line)
+		}
+	}
+	if g := ev.Git; g != nil {
+		line := "git: HEAD " + g.Head
+		if len(g.Branches) > 0 {
+			line += fmt.Sprintf(", %d branches", len(g.Branches))
+		}
+		if g.TrackedFiles > 0 {
+			line += fmt.Sprintf(", %d tracked files", g.TrackedFiles)
+		}
+		out = append(out, line)
+		for _, r := range g.Remotes {
+			rl := "git remote " + r.Name + ": " + r.URL
+			if r.CredentialsEmbedded {
+				rl += " (credentials embedded)"
+			}
+			out = append(out, rl)
+		}
+	}
+	if d := ev.DSStore; d != nil {
+		switch {
+		case d.Error != "" && len(d.Entries) == 0:
+			out = append(out, "ds_store: "+d.Error)
+		default:
+			out = append(out, fmt.Sprintf("ds_store: %d names (%s), %d queued", len(d.Entries), strings.Join(firstN(d.Entries, 3), ", "), len(d.Enqueued)))
+		}
+	}
+	if l := ev.Listing; l != nil {
+		switch {
+		case l.Error != "":
+			out = append(out, "listing: "+l.Error)
+		default:
+			line := fmt.Sprintf("listing: %d entries", l.Total)
+			if l.Server != "" {
+				line += " (" + l.Server + ")"
+			}
+			if len(l.Enqueued) > 0 {
+				line += fmt.Sprintf(", %d queued", len(l.Enqueued))
+			}
+			out = append(out, line)
+		}
+	}
+	if api := ev.API; api != nil {
+		switch {
+		case api.Error != "":
+			out = append(out, "api: "+api.Error)
+		default:
+			line := fmt.Sprintf("api: %s %s (%s), %d operations, %d without security", api.Title, api.Version, api.Spec, api.Operations, len(api.Unauthenticated))
+			open := 0
+			for _, p := range api.Probes {
+				if p.Open {
+					open++
+				}
+			}
+			if len(api.Probes) > 0 {
+				line += fmt.Sprintf(", %d/%d probes open", open, len(api.Probes))
+			}
+			out = append(out, line)
+		}
+	}
+	if x := ev.Actuator; x != nil {
+		var high []string
+		for _, e := range x.Endpoints {
+			if e.Risk == scanner.SeverityHigh {
+				high = append(high, e.Name)
+			}
+		}
+		line := fmt.Sprintf("actuator: %d endpoints under %s", len(x.Endpoints), x.BasePath)
+		if len(high) > 0 {
+			line += " (high risk: " + strings.Join(high, ", ") + ")"
+		}
+		if x.Env != nil {
+			if x.Env.Masked {
+				line += ", env masked"
+			} else {
+				line += fmt.Sprintf(", env unmasked (%d secret keys)", len(x.Env.UnmaskedKeys))
+			}
+		}
+		out = append(out, line)
+	}
+	if p := ev.PHPInfo; p != nil {
+		line := "phpinfo: PHP " + p.Version
+		if p.LoadedConfigFile != "" {
+			line += ", config " + p.LoadedConfigFile
+		}
+		if len(p.SecretEnvVars) > 0 {
+			line += ", secret env: " + strings.Join(firstN(p.SecretEnvVars, 3), ", ")
+		}
+		out = append(out, line)
+	}
+	if st := ev.Status; st != nil {
+		line := "server-status: " + st.ServerVersion
+		if len(st.Clients) > 0 || len(st.Requests) > 0 {
+			line += fmt.Sprintf(", %d clients, %d requests", len(st.Clients), len(st.Requests))
+		}
+		out = append(out, strings.TrimSuffix(line, ": "))
+	}
+	if x := ev.Observability; x != nil {
+		line := x.Kind
+		if x.Version != "" {
+			line += " " + x.Version
+		}
+		if x.GoVersion != "" {
+			line += ", " + x.GoVersion
+		}
+		if x.Total > 0 {
+			line += fmt.Sprintf(", %d items", x.Total)
+			if len(x.Items) > 0 {
+				line += ": " + strings.Join(firstN(x.Items, 3), ", ")
+			}
+		}
+		out = append(out, "observability: "+line)
+	}
+	if g := ev.GraphQL; g != nil {
+		line := "graphql: " + g.Endpoint
+		if g.UI != "" {
+			line += " (" + g.UI + ")"
+		}
+		if g.Introspection {
+			line += fmt.Sprintf(", introspection on: %d types, %d fields, %d mutations", g.Types, g.Fields, len(g.Mutations))
+		} else {
+			line += ", introspection off"
+		}
+		out = append(out, line)
+	}
+	if js := ev.JavaScript; js != nil {
+		if len(js.Secrets) > 0 {
+			out = append(out, "javascript secrets: "+strings.Join(js.Secrets, ", "))
+		}
+		if sm := js.SourceMap; sm != nil {
+			line := "source map: " + sm.URL
+			if sm.Served {
+				line += fmt.Sprintf(", %d sources", sm.SourceCount)
+				if len(sm.Sources) > 0 {
+					line += ": " + strings.Join(firstN(sm.Sources, 3), ", ")
+				}
+			} else {
+				line += " (not served)"
+			}
+			out = append(out, line)
+		}
+	}
+	if r := ev.Robots; r != nil {
+		var entries []string
+		for _, e := range r.Entries {
+			entries = append(entries, e.Pattern)
+		}
+		out = append(out, fmt.Sprintf("robots.txt: %d sensitive entries, %d reachable: %s", len(r.Entries), r.Reachable, strings.Join(firstN(entries, 3), ", ")))
+	}
+	if s := ev.Sitemap; s != nil {
+		line := "sitemap: " + s.Sitemap
+		if s.LastMod != "" {
+			line += " (lastmod " + s.LastMod + ")"
+		}
+		out = append(out, line)
+	}
+	if m := ev.Index; m != nil {
+		line := "search index: " + m.URL
+		if m.Engine != "" {
+			line = "search index (" + m.Engine + "): " + m.URL
+		}
+		if m.Title != "" {
+			line += " \"" + m.Title + "\""
+		}
+		if m.Source != "" {
+			line += " from " + m.Source
+			if m.LastCrawled != "" {
+				line += ", last crawled " + m.LastCrawled
+			}
+		}
+		out = append(out, line)
+		if m.Snippet != "" {
+			out = append(out, "  "+truncate(m.Snippet, 100))
+		}
+	}
+	if d := ev.Debug; d != nil {
+		line := "debug: " + d.Framework
+		if len(d.Versions) > 0 {
+			line += " (" + strings.Join(d.Versions, ", ") + ")"
+		}
+		if len(d.Paths) > 0 {
+			line += ", paths: " + strings.Join(firstN(d.Paths, 3), ", ")
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func firstN(in []string, n int) []string {
+	if len(in) <= n {
+		return in
+	}
+	return in[:n]
+}
+
+// truncate shortens s to at most n runes, marking the cut with "...".
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return s
+}
+
 func discoveryTag(src scanner.DiscoverySource) string {
 	if src == "" {
 		return ""
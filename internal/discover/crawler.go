@@ -6,124 +6,245 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
-var linkAttrRe = regexp.MustCompile(`(?is)\b(?:href|src)\s*=\s*(?:"([^"]+)"|'([^']+)')`)
+// CrawlOptions configures CrawlSameOrigin. Zero values select the defaults noted per field.
+type CrawlOptions struct {
+	UserAgent string
+	Timeout   time.Duration
+	MaxDepth  int   // default 2
+	MaxPages  int   // default 20
+	MaxBytes  int64 // per page, default 256 KiB
+	Workers   int   // default 4
 
-func CrawlSameOrigin(parent context.Context, client *http.Client, base *url.URL, userAgent string, timeout time.Duration, maxDepth int, maxPages int, maxBytes int64) ([]string, error) {
-	if maxDepth <= 0 {
-		maxDepth = 2
+	// RespectRobots honours Disallow and Crawl-delay; disallowed URLs are still reported.
+	RespectRobots bool
+
+	// Include and Exclude filter paths; the start page is always fetched.
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+}
+
+// CrawlStats summarises one crawl. Skipped counts pages that were not fetched or not parsed.
+type CrawlStats struct {
+	Fetched       int      `json:"pages_fetched"`
+	Discovered    int      `json:"urls_discovered"`
+	Skipped       int      `json:"skipped"`
+	RobotsSkipped int      `json:"robots_skipped,omitempty"`
+	Errors        int      `json:"errors"`
+	ErrorMessages []string `json:"error_messages,omitempty"`
+}
+
+const (
+	maxCrawlDelay  = 10 * time.Second
+	maxCrawlErrors = 5
+)
+
+// CrawlSameOrigin crawls breadth-first from base with up to opts.Workers concurrent fetches and returns
+// the same-origin URLs it reaches, each with the first reference that led to it.
+func CrawlSameOrigin(parent context.Context, client *http.Client, base *url.URL, opts CrawlOptions) ([]Link, CrawlStats) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 2
 	}
-	if maxDepth > 2 {
-		maxDepth = 2
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = 20
 	}
-	if maxPages <= 0 {
-		maxPages = 20
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 256 << 10
 	}
-	if maxBytes <= 0 {
-		maxBytes = 256 << 10
+	if opts.Workers <= 0 {
+		opts.Workers = 4
 	}
 
-	type item struct {
-		u     *url.URL
-		depth int
+	var rules *RobotsGroup
+	if opts.RespectRobots {
+		rules = fetchRobotsRules(parent, client, base, opts.UserAgent, opts.Timeout)
+	}
+	delay := rules.crawlDelay()
+	if delay > maxCrawlDelay {
+		delay = maxCrawlDelay
 	}
 
 	start := &url.URL{Scheme: base.Scheme, Host: base.Host, Path: base.Path}
 	start.Fragment = ""
 	start.RawQuery = ""
 
-	visited := make(map[string]struct{}, maxPages)
-	discovered := make(map[string]struct{}, maxPages*3)
-
-	queue := make([]item, 0, maxPages)
-	queue = append(queue, item{u: start, depth: 0})
+	var (
+		mu         sync.Mutex
+		stats      CrawlStats
+		visited    = make(map[string]struct{}, opts.MaxPages)
+		discovered = make(map[string]Link, opts.MaxPages*3)
+		nextSlot   time.Time
+	)
+	discovered[crawlKey(start)] = Link{URL: canonicalURL(start), Origin: "start"}
 
-	for len(queue) > 0 && len(visited) < maxPages {
-		it := queue[0]
-		queue = queue[1:]
-
-		canon := canonicalURL(it.u)
-		if _, ok := visited[canon]; ok {
-			continue
+	// wait spaces out fetches by the crawl delay across all workers.
+	wait := func() bool {
+		if delay <= 0 {
+			return true
 		}
-		visited[canon] = struct{}{}
-		discovered[canon] = struct{}{}
-
-		ctx, cancel := context.WithTimeout(parent, timeout)
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, canon, nil)
-		if err != nil {
-			cancel()
-			continue
+		mu.Lock()
+		now := time.Now()
+		at := nextSlot
+		if at.Before(now) {
+			at = now
 		}
-		if userAgent != "" {
-			req.Header.Set("User-Agent", userAgent)
-		}
-		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			cancel()
-			continue
+		nextSlot = at.Add(delay)
+		mu.Unlock()
+		select {
+		case <-parent.Done():
+			return false
+		case <-time.After(time.Until(at)):
+			return true
 		}
+	}
 
-		ct := strings.ToLower(resp.Header.Get("Content-Type"))
-		if resp.StatusCode != http.StatusOK || !strings.Contains(ct, "html") {
-			resp.Body.Close()
-			cancel()
-			continue
-		}
+	level := []*url.URL{start}
+	for depth := 0; len(level) > 0 && depth <= opts.MaxDepth; depth++ {
+		var next []*url.URL
+		sem := make(chan struct{}, opts.Workers)
+		var wg sync.WaitGroup
 
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBytes))
-		resp.Body.Close()
-		cancel()
+		for _, u := range level {
+			key := crawlKey(u)
+			mu.Lock()
+			if _, ok := visited[key]; ok {
+				mu.Unlock()
+				continue
+			}
+			if len(visited) >= opts.MaxPages {
+				mu.Unlock()
+				break
+			}
+			visited[key] = struct{}{}
+			mu.Unlock()
 
-		links := extractLinks(string(body))
-		for _, l := range links {
-			u, ok := resolveSameOrigin(it.u, base, l)
-			if !ok {
+			if depth > 0 && !rules.Allowed(u.RequestURI()) {
+				mu.Lock()
+				stats.Skipped++
+				stats.RobotsSkipped++
+				mu.Unlock()
 				continue
 			}
-			cu := canonicalURL(u)
-			discovered[cu] = struct{}{}
-			if it.depth+1 <= maxDepth {
-				if _, ok := visited[cu]; !ok {
-					queue = append(queue, item{u: u, depth: it.depth + 1})
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(page *url.URL) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if !wait() {
+					return
 				}
-			}
+
+				links, fetched, err := fetchPageLinks(parent, client, page, opts)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err != nil:
+					stats.Errors++
+					if len(stats.ErrorMessages) < maxCrawlErrors {
+						stats.ErrorMessages = append(stats.ErrorMessages, err.Error())
+					}
+					return
+				case !fetched:
+					stats.Fetched++
+					stats.Skipped++
+					return
+				}
+				stats.Fetched++
+
+				for _, l := range links {
+					u, ok := resolveSameOrigin(l.base, base, l.URL)
+					if !ok || !opts.keep(u.Path) {
+						continue
+					}
+					key := crawlKey(u)
+					if _, ok := discovered[key]; !ok {
+						discovered[key] = Link{URL: canonicalURL(u), Origin: l.Origin, Referrer: canonicalURL(page)}
+						next = append(next, u)
+					}
+				}
+			}(u)
+		}
+		wg.Wait()
+		if parent.Err() != nil {
+			break
 		}
+		level = next
 	}
 
-	out := make([]string, 0, len(discovered))
-	for u := range discovered {
-		out = append(out, u)
+	out := make([]Link, 0, len(discovered))
+	for _, l := range discovered {
+		out = append(out, l)
 	}
-	return out, nil
+	stats.Discovered = len(out)
+	return out, stats
 }
 
-func extractLinks(html string) []string {
-	matches := linkAttrRe.FindAllStringSubmatch(html, -1)
-	out := make([]string, 0, len(matches))
-	for _, m := range matches {
-		var v string
-		if len(m) > 1 && m[1] != "" {
-			v = m[1]
-		} else if len(m) > 2 && m[2] != "" {
-			v = m[2]
+func (o CrawlOptions) keep(p string) bool {
+	for _, re := range o.Exclude {
+		if re.MatchString(p) {
+			return false
 		}
-		v = strings.TrimSpace(v)
-		if v == "" {
-			continue
+	}
+	if len(o.Include) == 0 {
+		return true
+	}
+	for _, re := range o.Include {
+		if re.MatchString(p) {
+			return true
 		}
-		low := strings.ToLower(v)
-		if strings.HasPrefix(low, "mailto:") || strings.HasPrefix(low, "javascript:") || strings.HasPrefix(low, "data:") || strings.HasPrefix(low, "tel:") {
-			continue
+	}
+	return false
+}
+
+type pageLink struct {
+	Link
+	base *url.URL
+}
+
+// fetchPageLinks reports fetched false for non-200 or non-HTML responses.
+func fetchPageLinks(parent context.Context, client *http.Client, page *url.URL, opts CrawlOptions) (links []pageLink, fetched bool, err error) {
+	ctx, cancel := context.WithTimeout(parent, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, canonicalURL(page), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || !strings.Contains(ct, "html") {
+		return nil, false, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes))
+
+	found, baseHref := extractLinks(string(body))
+	pageBase := page
+	if baseHref != "" {
+		if b, err := page.Parse(baseHref); err == nil {
+			pageBase = b
 		}
-		out = append(out, v)
 	}
-	return out
+	links = make([]pageLink, 0, len(found))
+	for _, l := range found {
+		links = append(links, pageLink{Link: l, base: pageBase})
+	}
+	return links, true, nil
 }
 
 func resolveSameOrigin(basePage *url.URL, origin *url.URL, raw string) (*url.URL, bool) {
@@ -135,7 +256,6 @@ func resolveSameOrigin(basePage *url.URL, origin *url.URL, raw string) (*url.URL
 		u = basePage.ResolveReference(u)
 	}
 	u.Fragment = ""
-	u.RawQuery = ""
 	if u.Scheme != "" && u.Scheme != origin.Scheme {
 		return nil, false
 	}
@@ -151,13 +271,34 @@ func resolveSameOrigin(basePage *url.URL, origin *url.URL, raw string) (*url.URL
 	return u, true
 }
 
+// canonicalURL drops the fragment and re-encodes the query with parameters sorted by name.
 func canonicalURL(u *url.URL) string {
 	c := *u
 	c.Fragment = ""
-	c.RawQuery = ""
+	c.RawQuery = c.Query().Encode()
 	if c.Path == "" {
 		c.Path = "/"
 	}
 	return c.String()
 }
 
+// crawlKey keys URLs by path and parameter names, so pages differing only in values are crawled once.
+func crawlKey(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	q := c.Query()
+	names := make([]string, 0, len(q))
+	for k := range q {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	c.RawQuery = ""
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if len(names) == 0 {
+		return c.String()
+	}
+	return c.String() + "?" + strings.Join(names, "&")
+}
+
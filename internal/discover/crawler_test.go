@@ -0,0 +1,137 @@
+package discover
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// crawlSite serves a small site and counts the requests per path.
+type crawlSite struct {
+	mu    sync.Mutex
+	hits  map[string]int
+	pages map[string]string
+}
+
+func newCrawlSite() *crawlSite {
+	return &crawlSite{hits: make(map[string]int), pages: map[string]string{
+		"/": `<a href="/a">a</a><a href="/b">b</a><a href="/private/x">p</a><a href="/skip/y">s</a>
+<a href="/item?id=1">1</a><a href="/item?id=2">2</a><a href="/a#top">again</a><a href="https://elsewhere.example/">x</a>`,
+		"/a":              `<a href="deeper">d</a>`,
+		"/deeper":         `<a href="/deeper/deepest">d</a>`,
+		"/deeper/deepest": `<a href="/never">n</a>`,
+		"/b":              `<a href="/">home</a><a href="/a">a</a>`,
+		"/private/x":      `<a href="/private/y">y</a>`,
+		"/skip/y":         ``,
+		"/item":           `<a href="/item?id=3">3</a>`,
+		"/robots.txt":     "User-agent: wdf\nDisallow: /private\n",
+	}}
+}
+
+func (s *crawlSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.hits[r.URL.Path]++
+	s.mu.Unlock()
+	body, ok := s.pages[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.URL.Path == "/robots.txt" {
+		w.Header().Set("Content-Type", "text/plain")
+	} else {
+		w.Header().Set("Content-Type", "text/html")
+	}
+	fmt.Fprint(w, body)
+}
+
+func (s *crawlSite) hitCount(p string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[p]
+}
+
+func TestCrawlSameOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        CrawlOptions
+		wantURLs    []string
+		wantFetched int
+		wantRobots  int
+		notFetched  []string
+	}{
+		{
+			// /item?id=3 shares its key with /item?id=1; /deeper/deepest is found at the last
+			// level but not fetched.
+			name:        "depth and dedupe",
+			opts:        CrawlOptions{MaxDepth: 2},
+			wantURLs:    []string{"/", "/a", "/b", "/deeper", "/deeper/deepest", "/item?id=1", "/private/x", "/private/y", "/skip/y"},
+			wantFetched: 8,
+			notFetched:  []string{"/deeper/deepest"},
+		},
+		{
+			name:        "robots disallow",
+			opts:        CrawlOptions{MaxDepth: 2, RespectRobots: true},
+			wantURLs:    []string{"/", "/a", "/b", "/deeper", "/deeper/deepest", "/item?id=1", "/private/x", "/skip/y"},
+			wantFetched: 6,
+			wantRobots:  1,
+			notFetched:  []string{"/private/x"},
+		},
+		{
+			name:        "include and exclude",
+			opts:        CrawlOptions{MaxDepth: 3, Include: []*regexp.Regexp{regexp.MustCompile(`^/(a|b|deeper|skip)`)}, Exclude: []*regexp.Regexp{regexp.MustCompile(`^/skip`)}},
+			wantURLs:    []string{"/", "/a", "/b", "/deeper", "/deeper/deepest"},
+			wantFetched: 5,
+			notFetched:  []string{"/skip/y", "/item"},
+		},
+		{
+			name:        "page limit",
+			opts:        CrawlOptions{MaxDepth: 5, MaxPages: 3},
+			wantFetched: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := newCrawlSite()
+			srv := httptest.NewServer(site)
+			defer srv.Close()
+			base, _ := url.Parse(srv.URL + "/")
+
+			opts := tt.opts
+			opts.UserAgent = "wdf/1.0"
+			opts.Timeout = 5 * time.Second
+			links, stats := CrawlSameOrigin(context.Background(), srv.Client(), base, opts)
+
+			if tt.wantURLs != nil {
+				var got []string
+				for _, l := range links {
+					got = append(got, l.URL[len(srv.URL):])
+				}
+				sort.Strings(got)
+				if fmt.Sprint(got) != fmt.Sprint(tt.wantURLs) {
+					t.Errorf("urls = %q\nwant %q", got, tt.wantURLs)
+				}
+			}
+			if stats.Fetched != tt.wantFetched || stats.RobotsSkipped != tt.wantRobots || stats.Discovered != len(links) {
+				t.Errorf("stats = %+v, want %d fetched, %d robots-skipped", stats, tt.wantFetched, tt.wantRobots)
+			}
+			for _, p := range tt.notFetched {
+				if n := site.hitCount(p); n != 0 {
+					t.Errorf("%s fetched %d times", p, n)
+				}
+			}
+			for p, n := range site.hits {
+				if n > 1 && p != "/robots.txt" {
+					t.Errorf("%s fetched %d times", p, n)
+				}
+			}
+		})
+	}
+}
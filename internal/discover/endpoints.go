@@ -0,0 +1,274 @@
+package discover
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// Quoted absolute paths and URLs in JS string literals and inline JSON.
+	literalPathRe = regexp.MustCompile("[\"'`]((?:https?://[A-Za-z0-9.\\-:]+)?/[A-Za-z0-9_\\-.~%/:{}$@+=,]*(?:\\?[A-Za-z0-9_\\-.~%/:{}$@+=,&\\[\\]]*)?)[\"'`?#]")
+	// Arguments of HTTP client calls, which may also be relative.
+	httpCallRe    = regexp.MustCompile("(?:\\bfetch|\\baxios(?:\\.(?:get|post|put|patch|delete|head|request))?|\\$\\.(?:get|post|ajax|getJSON)|\\.open\\(\\s*[\"'][A-Za-z]+[\"']\\s*,)\\s*\\(?\\s*[\"'`]([^\"'`\\s]+)[\"'`]")
+	htmlCommentRe = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+	commentPathRe = regexp.MustCompile(`(?:^|[\s(=])((?:https?://[A-Za-z0-9.\-:]+)?/[A-Za-z0-9_\-.~%/]+)`)
+	dataAttrRe    = regexp.MustCompile(`(?i)\bdata-[a-z0-9\-]+\s*=\s*(?:"([^"]+)"|'([^']+)')`)
+)
+
+// EndpointStats counts the files FetchEndpoints requested; Errors omits 404s.
+type EndpointStats struct {
+	Fetched int      `json:"fetched"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+const maxEndpointErrors = 5
+
+var staticAssetExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".eot": true, ".otf": true, ".css": true, ".mp4": true, ".mp3": true,
+}
+
+// FetchEndpoints downloads same-origin pages and the scripts they load, up to maxFetch, and returns
+// the URLs referenced in them, resolved against the referencing document.
+func FetchEndpoints(parent context.Context, client *http.Client, base *url.URL, pages []string, userAgent string, timeout time.Duration, maxBytes int64, maxFetch int) ([]Link, EndpointStats) {
+	if maxFetch <= 0 {
+		maxFetch = 50
+	}
+	if maxBytes <= 0 {
+		maxBytes = 2 << 20
+	}
+
+	found := make([]Link, 0, 256)
+	seen := make(map[string]struct{}, 256)
+	// document is what relative references resolve against, when it is not the file itself.
+	type queuedFile struct {
+		url      string
+		document *url.URL
+	}
+	queue := make([]queuedFile, 0, len(pages))
+	queued := make(map[string]struct{}, len(pages))
+	for _, p := range pages {
+		queue = append(queue, queuedFile{url: p})
+		queued[p] = struct{}{}
+	}
+	var stats EndpointStats
+
+	for i := 0; i < len(queue) && stats.Fetched+stats.Failed < maxFetch; i++ {
+		u, err := url.Parse(queue[i].url)
+		if err != nil || !strings.EqualFold(u.Host, base.Host) {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(u.Path))
+		if ext != "" && ext != ".js" && ext != ".mjs" && ext != ".html" && ext != ".htm" {
+			continue
+		}
+
+		body, fetched, err := fetchText(parent, client, u.String(), userAgent, timeout, maxBytes)
+		if err != nil {
+			stats.Failed++
+			var se *HTTPStatusError
+			if (!errors.As(err, &se) || se.StatusCode != http.StatusNotFound) && len(stats.Errors) < maxEndpointErrors {
+				stats.Errors = append(stats.Errors, err.Error())
+			}
+			continue
+		}
+		stats.Fetched++
+		if !fetched {
+			continue
+		}
+		isScript := ext == ".js" || ext == ".mjs"
+		var links []Link
+		document := u
+		if !isScript {
+			var baseHref string
+			links, baseHref = extractLinks(body)
+			if baseHref != "" {
+				if b, err := u.Parse(baseHref); err == nil {
+					document = b
+				}
+			}
+		} else if queue[i].document != nil {
+			document = queue[i].document
+		}
+
+		for _, e := range ExtractEndpoints(body) {
+			ref, err := url.Parse(e)
+			if err != nil {
+				continue
+			}
+			abs := document.ResolveReference(ref).String()
+			if _, dup := seen[abs]; dup {
+				continue
+			}
+			seen[abs] = struct{}{}
+			found = append(found, Link{URL: abs, Referrer: u.String()})
+		}
+		if isScript {
+			continue
+		}
+		for _, s := range scriptSources(document, base, links) {
+			if _, dup := queued[s]; !dup {
+				queued[s] = struct{}{}
+				queue = append(queue, queuedFile{url: s, document: document})
+			}
+		}
+	}
+	return found, stats
+}
+
+// scriptSources returns the same-origin <script src> URLs of a page.
+func scriptSources(pageBase, origin *url.URL, links []Link) []string {
+	var out []string
+	for _, l := range links {
+		if l.Origin != "script[src]" {
+			continue
+		}
+		if u, ok := resolveSameOrigin(pageBase, origin, l.URL); ok {
+			out = append(out, canonicalURL(u))
+		}
+	}
+	return out
+}
+
+// fetchText reports fetched false for types other than HTML or JavaScript.
+func fetchText(parent context.Context, client *http.Client, rawURL, userAgent string, timeout time.Duration, maxBytes int64) (body string, fetched bool, err error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	req.Header.Set("Accept", "text/html,application/javascript,*/*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", false, &HTTPStatusError{URL: rawURL, StatusCode: resp.StatusCode}
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if ct != "" && !strings.Contains(ct, "html") && !strings.Contains(ct, "javascript") && !strings.Contains(ct, "ecmascript") && !strings.Contains(ct, "text/plain") {
+		return "", false, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	if err != nil && len(b) == 0 {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
+
+// ExtractEndpoints returns candidate paths found in JS or HTML, cut at their first template placeholder.
+func ExtractEndpoints(content string) []string {
+	var raw []string
+	for _, m := range literalPathRe.FindAllStringSubmatch(content, -1) {
+		raw = append(raw, m[1])
+	}
+	for _, m := range httpCallRe.FindAllStringSubmatch(content, -1) {
+		raw = append(raw, m[1])
+	}
+	for _, c := range htmlCommentRe.FindAllStringSubmatch(content, -1) {
+		for _, m := range commentPathRe.FindAllStringSubmatch(c[1], -1) {
+			raw = append(raw, m[1])
+		}
+	}
+	for _, m := range dataAttrRe.FindAllStringSubmatch(content, -1) {
+		v := m[1]
+		if v == "" {
+			v = m[2]
+		}
+		if strings.HasPrefix(v, "/") || strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+			raw = append(raw, v)
+		}
+	}
+
+	out := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, r := range raw {
+		e, ok := cleanEndpoint(r)
+		if !ok {
+			continue
+		}
+		if _, dup := seen[e]; dup {
+			continue
+		}
+		seen[e] = struct{}{}
+		out = append(out, e)
+	}
+	return out
+}
+
+// cleanEndpoint cuts a candidate at its first dynamic segment and drops dynamic query parameters.
+func cleanEndpoint(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '#'); i >= 0 {
+		s = s[:i]
+	}
+	s, query, _ := strings.Cut(s, "?")
+	if strings.HasPrefix(s, "//") || s == "" || s == "/" {
+		return "", false
+	}
+	low := strings.ToLower(s)
+	if strings.HasPrefix(low, "javascript:") || strings.HasPrefix(low, "data:") || strings.HasPrefix(low, "mailto:") {
+		return "", false
+	}
+
+	// Drop everything from the first dynamic segment: ${id}, :id, {id} or *.
+	prefix, rest := "", s
+	if i := strings.Index(s, "://"); i >= 0 {
+		j := strings.IndexByte(s[i+3:], '/')
+		if j < 0 {
+			return "", false
+		}
+		prefix, rest = s[:i+3+j], s[i+3+j:]
+	}
+	segs := strings.Split(rest, "/")
+	for i, seg := range segs {
+		if strings.ContainsAny(seg, dynamicChars) {
+			segs = segs[:i]
+			query = ""
+			break
+		}
+	}
+	rest = strings.Join(segs, "/")
+	if rest == "" || rest == "/" {
+		return "", false
+	}
+	if staticAssetExts[strings.ToLower(path.Ext(rest))] {
+		return "", false
+	}
+	if q := staticQuery(query); q != "" {
+		rest += "?" + q
+	}
+	return prefix + rest, true
+}
+
+const dynamicChars = "${}*:"
+
+func staticQuery(query string) string {
+	var kept []string
+	for _, pair := range strings.Split(query, "&") {
+		k, v, _ := strings.Cut(pair, "=")
+		if k == "" || strings.ContainsAny(k, dynamicChars) {
+			continue
+		}
+		if strings.ContainsAny(v, dynamicChars) {
+			v = ""
+		}
+		kept = append(kept, k+"="+v)
+	}
+	return strings.Join(kept, "&")
+}
@@ -0,0 +1,125 @@
+package discover
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFetchEndpointsFollowsScripts(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><script src="/static/app.js?v=3"></script><script src="https://cdn.example.net/lib.js"></script>
+<script src="/static/app.js?v=3"></script><script src="vendor.js"></script>
+<script src="/broken.js"></script><script src="/missing.js"></script></html>`))
+	})
+	mux.HandleFunc("/static/app.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Write([]byte(`fetch("/api/v1/users")`))
+	})
+	mux.HandleFunc("/vendor.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Write([]byte(`axios.get("/api/v1/orders")`))
+	})
+	mux.HandleFunc("/broken.js", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/missing.js", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	base, _ := url.Parse(srv.URL)
+
+	fetch := func(maxFetch int) (map[string]string, EndpointStats) {
+		links, stats := FetchEndpoints(context.Background(), srv.Client(), base, []string{srv.URL + "/"}, "wdf-test", 5*time.Second, 0, maxFetch)
+		got := make(map[string]string)
+		for _, l := range links {
+			got[l.URL] = l.Referrer
+		}
+		return got, stats
+	}
+
+	got, stats := fetch(10)
+	if stats.Fetched != 3 || stats.Failed != 2 || len(stats.Errors) != 1 {
+		// The 404 of /missing.js counts as failed but is not an error worth reporting.
+		t.Errorf("stats = %+v, want 3 fetched, 2 failed, 1 error", stats)
+	}
+	if ref := got[srv.URL+"/api/v1/users"]; ref != srv.URL+"/static/app.js?v=3" {
+		t.Errorf("/api/v1/users referrer = %q, want the bundle (%v)", ref, got)
+	}
+	if _, ok := got[srv.URL+"/api/v1/orders"]; !ok {
+		t.Errorf("relative script not mined: %v", got)
+	}
+
+	// The start page and one script use up the budget.
+	got, _ = fetch(2)
+	if _, ok := got[srv.URL+"/api/v1/orders"]; ok {
+		t.Errorf("maxFetch exceeded: %v", got)
+	}
+}
+
+func TestFetchEndpointsResolvesAgainstDocument(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/index.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><script src="static/main.js"></script><script>fetch("session")</script></html>`))
+	})
+	mux.HandleFunc("/app/static/main.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Write([]byte(`fetch("api/items?page=2"); const u = "/download?file=" + name;`))
+	})
+	mux.HandleFunc("/shop", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><base href="/v2/"></head><script>axios.get("orders")</script></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	base, _ := url.Parse(srv.URL)
+
+	links, _ := FetchEndpoints(context.Background(), srv.Client(), base, []string{srv.URL + "/app/index.html", srv.URL + "/shop"}, "wdf-test", 5*time.Second, 0, 10)
+	got := make(map[string]string)
+	for _, l := range links {
+		got[l.URL] = l.Referrer
+	}
+	want := map[string]string{
+		// The bundle's relative calls resolve against the page that loaded it, not the bundle.
+		srv.URL + "/app/session":          srv.URL + "/app/index.html",
+		srv.URL + "/app/api/items?page=2": srv.URL + "/app/static/main.js",
+		srv.URL + "/download?file=":       srv.URL + "/app/static/main.js",
+		srv.URL + "/v2/orders":            srv.URL + "/shop",
+	}
+	for u, ref := range want {
+		if got[u] != ref {
+			t.Errorf("%s referrer = %q, want %q (all: %v)", u, got[u], ref, got)
+		}
+	}
+}
+
+func TestExtractEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "literal path", content: `const r = "/api/v1/users";`, want: []string{"/api/v1/users"}},
+		{name: "literal query kept", content: `var u = "/download?file=report.pdf";`, want: []string{"/download?file=report.pdf"}},
+		{name: "query value dynamic", content: "fetch(`/search?q=${term}&page=1`)", want: []string{"/search?q=&page=1"}},
+		{name: "dynamic segment drops query", content: "fetch(`/users/${id}?expand=1`)", want: []string{"/users"}},
+		{name: "fragment dropped", content: `location = "/help#faq";`, want: []string{"/help"}},
+		{name: "relative call", content: `fetch("api/x")`, want: []string{"api/x"}},
+		{name: "route parameter", content: `{path: "/orders/:id/edit"}`, want: []string{"/orders"}},
+		{name: "static asset", content: `img.src = "/img/logo.png";`},
+		{name: "protocol relative", content: `s = "//cdn.example.net/x";`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractEndpoints(tt.content); !reflect.DeepEqual(got, tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+				t.Errorf("ExtractEndpoints = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package discover
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HTTPStatusError reports a discovery resource that was answered with a non-200 status.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("GET %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
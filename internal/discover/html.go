@@ -0,0 +1,313 @@
+package discover
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Link is a reference found in an HTML page. Origin names the construct it came from, such as
+// "a[href]" or "comment a[href]"; Referrer is the page or file it was found in.
+type Link struct {
+	URL      string
+	Origin   string
+	Referrer string
+}
+
+var (
+	cssURLRe      = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)`)
+	cssImportRe   = regexp.MustCompile(`(?i)@import\s+(?:"([^"]+)"|'([^']+)')`)
+	commentRefRe  = regexp.MustCompile(`(?:^|\s)((?:https?://|/)[^\s"'<>()]+)`)
+	metaRefreshRe = regexp.MustCompile(`(?i)^\s*\d*\s*[;,]?\s*url\s*=\s*['"]?([^'"]+)['"]?\s*$`)
+)
+
+// linkAttrs lists the attributes that hold URLs, per element.
+var linkAttrs = map[string][]string{
+	"a":          {"href"},
+	"area":       {"href"},
+	"link":       {"href"},
+	"img":        {"src", "srcset", "lowsrc", "longdesc"},
+	"source":     {"src", "srcset"},
+	"script":     {"src"},
+	"iframe":     {"src"},
+	"frame":      {"src", "longdesc"},
+	"embed":      {"src"},
+	"audio":      {"src"},
+	"video":      {"src", "poster"},
+	"track":      {"src"},
+	"input":      {"src", "formaction"},
+	"button":     {"formaction"},
+	"form":       {"action"},
+	"object":     {"data"},
+	"blockquote": {"cite"},
+	"q":          {"cite"},
+	"ins":        {"cite"},
+	"del":        {"cite"},
+	"body":       {"background"},
+	"table":      {"background"},
+	"td":         {"background"},
+}
+
+// rawTextElements hold text that must not be tokenized as markup.
+var rawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true, "xmp": true, "noembed": true}
+
+type htmlAttr struct {
+	name, value string
+}
+
+// extractLinks returns the links of page and its first <base href>.
+func extractLinks(page string) (links []Link, baseHref string) {
+	t := &linkTokenizer{}
+	t.run(page, "")
+	return t.links, t.base
+}
+
+type linkTokenizer struct {
+	links []Link
+	base  string
+	// inComment is set while tokenizing the contents of an HTML comment, which are not nested.
+	inComment bool
+}
+
+func (t *linkTokenizer) add(raw, origin string) {
+	v := strings.TrimSpace(html.UnescapeString(raw))
+	if v == "" || strings.HasPrefix(v, "#") {
+		return
+	}
+	low := strings.ToLower(v)
+	if strings.HasPrefix(low, "mailto:") || strings.HasPrefix(low, "javascript:") || strings.HasPrefix(low, "data:") || strings.HasPrefix(low, "tel:") {
+		return
+	}
+	t.links = append(t.links, Link{URL: v, Origin: origin})
+}
+
+func (t *linkTokenizer) run(s, originPrefix string) {
+	i := 0
+	for i < len(s) {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			return
+		}
+		i += lt
+		rest := s[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--") && t.inComment:
+			// Comments do not nest; inside one the opener is plain text.
+			i += 4
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			body := rest[4:]
+			if end >= 0 {
+				body = rest[4 : 4+end]
+				i += 4 + end + 3
+			} else {
+				i = len(s)
+			}
+			// Commented-out markup often still points at live resources.
+			t.inComment = true
+			t.run(body, "comment ")
+			t.inComment = false
+			for _, m := range commentRefRe.FindAllStringSubmatch(body, -1) {
+				t.add(m[1], "comment text")
+			}
+		case strings.HasPrefix(rest, "</"), strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return
+			}
+			i += end + 1
+		case len(rest) > 1 && isASCIILetter(rest[1]):
+			name, attrs, n := parseTag(rest)
+			i += n
+			t.handleTag(name, attrs, originPrefix)
+			if rawTextElements[name] {
+				closeIdx := indexFold(s[i:], "</"+name)
+				text := s[i:]
+				if closeIdx >= 0 {
+					text = s[i : i+closeIdx]
+					i += closeIdx
+				} else {
+					i = len(s)
+				}
+				if name == "style" {
+					t.addCSS(text, originPrefix+"style url()")
+				}
+			}
+		default:
+			i++
+		}
+	}
+}
+
+func (t *linkTokenizer) handleTag(name string, attrs []htmlAttr, originPrefix string) {
+	get := func(key string) (string, bool) {
+		for _, a := range attrs {
+			if a.name == key {
+				return a.value, true
+			}
+		}
+		return "", false
+	}
+
+	switch name {
+	case "base":
+		if v, ok := get("href"); ok && t.base == "" && !t.inComment {
+			t.base = strings.TrimSpace(html.UnescapeString(v))
+		}
+		return
+	case "meta":
+		if eq, _ := get("http-equiv"); strings.EqualFold(strings.TrimSpace(eq), "refresh") {
+			if c, ok := get("content"); ok {
+				if m := metaRefreshRe.FindStringSubmatch(html.UnescapeString(c)); m != nil {
+					t.add(m[1], originPrefix+"meta[refresh]")
+				}
+			}
+		}
+	case "link":
+		if v, ok := get("href"); ok {
+			rel, _ := get("rel")
+			origin := "link[href]"
+			if strings.Contains(strings.ToLower(rel), "canonical") {
+				origin = "link[canonical]"
+			}
+			t.add(v, originPrefix+origin)
+		}
+	}
+
+	for _, key := range linkAttrs[name] {
+		if name == "link" && key == "href" {
+			continue
+		}
+		v, ok := get(key)
+		if !ok {
+			continue
+		}
+		origin := originPrefix + name + "[" + key + "]"
+		if key == "srcset" {
+			for _, cand := range parseSrcset(html.UnescapeString(v)) {
+				t.add(cand, origin)
+			}
+			continue
+		}
+		t.add(v, origin)
+	}
+	if v, ok := get("style"); ok {
+		t.addCSS(html.UnescapeString(v), originPrefix+name+"[style] url()")
+	}
+}
+
+func (t *linkTokenizer) addCSS(css, origin string) {
+	for _, re := range []*regexp.Regexp{cssURLRe, cssImportRe} {
+		for _, m := range re.FindAllStringSubmatch(css, -1) {
+			for _, v := range m[1:] {
+				if v != "" {
+					t.add(v, origin)
+					break
+				}
+			}
+		}
+	}
+}
+
+// parseTag reads a start tag at the beginning of s and returns the number of bytes consumed.
+func parseTag(s string) (name string, attrs []htmlAttr, n int) {
+	i := 1
+	for i < len(s) && !isTagSpace(s[i]) && s[i] != '>' && s[i] != '/' {
+		i++
+	}
+	name = strings.ToLower(s[1:i])
+
+	for i < len(s) {
+		for i < len(s) && (isTagSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return name, attrs, i + 1
+		}
+		start := i
+		for i < len(s) && !isTagSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		key := strings.ToLower(s[start:i])
+		for i < len(s) && isTagSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) || s[i] != '=' {
+			if key != "" {
+				attrs = append(attrs, htmlAttr{name: key})
+			}
+			continue
+		}
+		i++
+		for i < len(s) && isTagSpace(s[i]) {
+			i++
+		}
+		var val string
+		if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+			q := s[i]
+			end := strings.IndexByte(s[i+1:], q)
+			if end < 0 {
+				val = s[i+1:]
+				i = len(s)
+			} else {
+				val = s[i+1 : i+1+end]
+				i += end + 2
+			}
+		} else {
+			vs := i
+			for i < len(s) && !isTagSpace(s[i]) && s[i] != '>' {
+				i++
+			}
+			val = s[vs:i]
+		}
+		if key != "" {
+			attrs = append(attrs, htmlAttr{name: key, value: val})
+		}
+	}
+	return name, attrs, len(s)
+}
+
+// parseSrcset returns the URLs of a srcset list such as "a.png 1x, b.png 2x".
+func parseSrcset(v string) []string {
+	var out []string
+	for _, cand := range strings.Split(v, ",") {
+		f := strings.Fields(cand)
+		if len(f) > 0 {
+			out = append(out, f[0])
+		}
+	}
+	return out
+}
+
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// indexFold is an ASCII case-insensitive strings.Index; sub must be lower case.
+func indexFold(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := 0; j < len(sub); j++ {
+			c := s[i+j]
+			if c >= 'A' && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			if c != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
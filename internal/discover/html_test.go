@@ -0,0 +1,133 @@
+package discover
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []Link
+		base string
+	}{
+		{
+			name: "quoted and unquoted attributes",
+			page: `<a href="/a">a</a><A HREF='/b'>b</A><a href=/c>c</a><img src = "/d.png" alt=x>`,
+			want: []Link{{URL: "/a", Origin: "a[href]"}, {URL: "/b", Origin: "a[href]"}, {URL: "/c", Origin: "a[href]"}, {URL: "/d.png", Origin: "img[src]"}},
+		},
+		{
+			name: "entities and skipped schemes",
+			page: `<a href="/search?q=1&amp;p=2">s</a><a href="mailto:x@example.com">m</a><a href="javascript:void(0)">j</a><a href="#top">t</a>`,
+			want: []Link{{URL: "/search?q=1&p=2", Origin: "a[href]"}},
+		},
+		{
+			name: "srcset",
+			page: `<img srcset="/s.png 1x, /l.png 2x"><picture><source srcset="/w.webp 640w,/x.webp 1280w"></picture>`,
+			want: []Link{{URL: "/s.png", Origin: "img[srcset]"}, {URL: "/l.png", Origin: "img[srcset]"}, {URL: "/w.webp", Origin: "source[srcset]"}, {URL: "/x.webp", Origin: "source[srcset]"}},
+		},
+		{
+			name: "meta refresh",
+			page: `<meta http-equiv="Refresh" content="5; URL='/moved'"><meta name="description" content="url=/nope">`,
+			want: []Link{{URL: "/moved", Origin: "meta[refresh]"}},
+		},
+		{
+			name: "base href",
+			page: `<head><base href="/app/"><base href="/ignored/"></head><a href="page">p</a>`,
+			want: []Link{{URL: "page", Origin: "a[href]"}},
+			base: "/app/",
+		},
+		{
+			name: "canonical link",
+			page: `<link rel="canonical" href="https://example.com/p"><link rel="stylesheet" href="/s.css">`,
+			want: []Link{{URL: "https://example.com/p", Origin: "link[canonical]"}, {URL: "/s.css", Origin: "link[href]"}},
+		},
+		{
+			name: "style url",
+			page: `<style>@import "/theme.css"; body { background: url('/bg.jpg') }</style><div style="background-image: url(/hero.jpg)"></div>`,
+			want: []Link{{URL: "/bg.jpg", Origin: "style url()"}, {URL: "/theme.css", Origin: "style url()"}, {URL: "/hero.jpg", Origin: "div[style] url()"}},
+		},
+		{
+			name: "comments",
+			page: `<!-- <a href="/old-admin">admin</a> see /backup/db.sql --><a href="/live">l</a>`,
+			want: []Link{{URL: "/old-admin", Origin: "comment a[href]"}, {URL: "/backup/db.sql", Origin: "comment text"}, {URL: "/live", Origin: "a[href]"}},
+		},
+		{
+			name: "base inside a comment is ignored",
+			page: `<!-- <base href="/old/"> --><a href="x">x</a>`,
+			want: []Link{{URL: "x", Origin: "a[href]"}},
+		},
+		{
+			name: "script text is not markup",
+			page: `<script src="/app.js">document.write('<a href="/fake">')</script><a href="/real">r</a>`,
+			want: []Link{{URL: "/app.js", Origin: "script[src]"}, {URL: "/real", Origin: "a[href]"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, base := extractLinks(tt.page)
+			if !reflect.DeepEqual(links, tt.want) && !(len(links) == 0 && len(tt.want) == 0) {
+				t.Errorf("links = %+v\nwant %+v", links, tt.want)
+			}
+			if base != tt.base {
+				t.Errorf("base = %q, want %q", base, tt.base)
+			}
+		})
+	}
+}
+
+func TestExtractLinksMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []Link
+	}{
+		{name: "unterminated double quote", page: `<a href="/a>text</a><a href="/b">`, want: []Link{{URL: "/a>text</a><a href=", Origin: "a[href]"}}},
+		{name: "unterminated single quote", page: `<img src='/x.png`, want: []Link{{URL: "/x.png", Origin: "img[src]"}}},
+		{name: "unterminated comment", page: `<a href="/a">a</a><!-- <a href="/b">`, want: []Link{{URL: "/a", Origin: "a[href]"}, {URL: "/b", Origin: "comment a[href]"}}},
+		{name: "unterminated tag", page: `<a href=/a`, want: []Link{{URL: "/a", Origin: "a[href]"}}},
+		{name: "unterminated end tag", page: `<a href="/a"></a`, want: []Link{{URL: "/a", Origin: "a[href]"}}},
+		{name: "unterminated script", page: `<script>var a = "<a href='/x'>"`},
+		{name: "lone angle brackets", page: `a < b > c <<>> <`},
+		{name: "nested comment openers", page: `<!-- <!-- <a href="/c"> -->`, want: []Link{{URL: "/c", Origin: "comment a[href]"}}},
+		{name: "empty attribute value", page: `<a href= >x</a><a href=>y</a>`},
+		{name: "repeated garbage", page: strings.Repeat(`<a href="`, 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan []Link, 1)
+			go func() {
+				links, _ := extractLinks(tt.page)
+				done <- links
+			}()
+			select {
+			case links := <-done:
+				if tt.want != nil && !reflect.DeepEqual(links, tt.want) {
+					t.Errorf("links = %+v\nwant %+v", links, tt.want)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("tokenizer did not terminate")
+			}
+		})
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"/a.png", []string{"/a.png"}},
+		{"/a.png 1x, /b.png 2x", []string{"/a.png", "/b.png"}},
+		{" /a.png  320w ,\n/b.png 640w ,", []string{"/a.png", "/b.png"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseSrcset(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSrcset(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -6,18 +6,35 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func FetchRobots(parent context.Context, client *http.Client, base *url.URL, userAgent string, timeout time.Duration, maxBytes int64) ([]string, []string, error) {
+// RobotsFile is a parsed robots.txt; rules for one crawler are selected with Group.
+type RobotsFile struct {
+	Groups   []RobotsGroup `json:"groups,omitempty"`
+	Sitemaps []string      `json:"sitemaps,omitempty"`
+}
+
+// RobotsGroup is one group of rules, with * and $ wildcards kept as written. CrawlDelay is in seconds.
+type RobotsGroup struct {
+	Agents     []string `json:"user_agents"`
+	Allow      []string `json:"allow,omitempty"`
+	Disallow   []string `json:"disallow,omitempty"`
+	CrawlDelay float64  `json:"crawl_delay,omitempty"`
+}
+
+// FetchRobots downloads and parses /robots.txt; a non-200 response yields an *HTTPStatusError.
+func FetchRobots(parent context.Context, client *http.Client, base *url.URL, userAgent string, timeout time.Duration, maxBytes int64) (*RobotsFile, error) {
 	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
 	robotsURL := (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: "/robots.txt"}).String()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if userAgent != "" {
 		req.Header.Set("User-Agent", userAgent)
@@ -26,75 +43,278 @@ func FetchRobots(parent context.Context, client *http.Client, base *url.URL, use
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, nil, nil
+		return nil, &HTTPStatusError{URL: robotsURL, StatusCode: resp.StatusCode}
 	}
 
+	// RFC 9309 requires parsing at least 500 KiB.
 	if maxBytes <= 0 {
 		maxBytes = 1 << 20
 	}
-	r := io.LimitReader(resp.Body, maxBytes)
+	return ParseRobots(io.LimitReader(resp.Body, maxBytes))
+}
 
-	paths := make([]string, 0, 64)
-	sitemaps := make([]string, 0, 4)
-	seenP := make(map[string]struct{})
-	seenS := make(map[string]struct{})
+// ParseRobots parses robots.txt following RFC 9309. Rules outside a group are ignored; Sitemap lines
+// apply to the whole file.
+func ParseRobots(r io.Reader) (*RobotsFile, error) {
+	f := &RobotsFile{}
+	var current *RobotsGroup
+	inAgents := false
+	seenSitemap := make(map[string]struct{})
 
 	sc := bufio.NewScanner(r)
+	first := true
 	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
-		if line == "" {
-			continue
+		line := sc.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
 		}
 		if i := strings.IndexByte(line, '#'); i >= 0 {
-			line = strings.TrimSpace(line[:i])
-			if line == "" {
-				continue
-			}
+			line = line[:i]
 		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(v)
 
-		lower := strings.ToLower(line)
-		switch {
-		case strings.HasPrefix(lower, "disallow:"):
-			v := strings.TrimSpace(line[len("disallow:"):])
-			if v == "" || v == "/" {
-				continue
+		switch k {
+		case "user-agent":
+			if !inAgents {
+				f.Groups = append(f.Groups, RobotsGroup{})
+				current = &f.Groups[len(f.Groups)-1]
+				inAgents = true
 			}
-			if strings.HasPrefix(v, "/") {
-				if _, ok := seenP[v]; !ok {
-					seenP[v] = struct{}{}
-					paths = append(paths, v)
-				}
+			if v != "" {
+				current.Agents = append(current.Agents, v)
 			}
-		case strings.HasPrefix(lower, "allow:"):
-			v := strings.TrimSpace(line[len("allow:"):])
-			if v == "" || v == "/" {
-				continue
+			continue
+		case "sitemap":
+			if _, dup := seenSitemap[v]; v != "" && !dup {
+				seenSitemap[v] = struct{}{}
+				f.Sitemaps = append(f.Sitemaps, v)
 			}
-			if strings.HasPrefix(v, "/") {
-				if _, ok := seenP[v]; !ok {
-					seenP[v] = struct{}{}
-					paths = append(paths, v)
-				}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		switch k {
+		case "allow":
+			inAgents = false
+			if v != "" {
+				current.Allow = append(current.Allow, v)
 			}
-		case strings.HasPrefix(lower, "sitemap:"):
-			v := strings.TrimSpace(line[len("sitemap:"):])
-			if v == "" {
-				continue
+		case "disallow":
+			inAgents = false
+			// An empty Disallow allows everything, which is the default.
+			if v != "" {
+				current.Disallow = append(current.Disallow, v)
 			}
-			if _, ok := seenS[v]; !ok {
-				seenS[v] = struct{}{}
-				sitemaps = append(sitemaps, v)
+		case "crawl-delay":
+			inAgents = false
+			if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 0 {
+				current.CrawlDelay = secs
 			}
 		}
 	}
 	if err := sc.Err(); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	// Drop groups whose User-agent lines were all empty.
+	groups := f.Groups[:0]
+	for _, g := range f.Groups {
+		if len(g.Agents) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	f.Groups = groups
+	return f, nil
+}
+
+// Group combines the groups naming agent, or else the "*" groups. A nil group allows everything.
+func (f *RobotsFile) Group(agent string) *RobotsGroup {
+	if f == nil {
+		return nil
+	}
+	agent = strings.ToLower(agent)
+	var specific, wildcard *RobotsGroup
+	for i := range f.Groups {
+		g := &f.Groups[i]
+		switch {
+		case agent != "" && g.names(agent):
+			specific = combineGroups(specific, g, agent)
+		case g.names("*"):
+			wildcard = combineGroups(wildcard, g, "*")
+		}
+	}
+	if specific != nil {
+		return specific
+	}
+	return wildcard
+}
+
+func (g *RobotsGroup) names(agent string) bool {
+	for _, a := range g.Agents {
+		if strings.ToLower(a) == agent {
+			return true
+		}
+	}
+	return false
+}
+
+func combineGroups(dst, g *RobotsGroup, agent string) *RobotsGroup {
+	if dst == nil {
+		dst = &RobotsGroup{Agents: []string{agent}}
+	}
+	dst.Allow = append(dst.Allow, g.Allow...)
+	dst.Disallow = append(dst.Disallow, g.Disallow...)
+	if g.CrawlDelay > dst.CrawlDelay {
+		dst.CrawlDelay = g.CrawlDelay
+	}
+	return dst
+}
+
+// Allowed reports whether p may be crawled: the longest matching pattern wins, Allow wins ties.
+func (g *RobotsGroup) Allowed(p string) bool {
+	if g == nil {
+		return true
 	}
-	return paths, sitemaps, nil
+	best, allow := -1, true
+	for _, d := range g.Disallow {
+		if len(d) > best && robotsMatch(d, p) {
+			best, allow = len(d), false
+		}
+	}
+	for _, a := range g.Allow {
+		if len(a) >= best && robotsMatch(a, p) {
+			best, allow = len(a), true
+		}
+	}
+	return allow
+}
+
+func (g *RobotsGroup) crawlDelay() time.Duration {
+	if g == nil {
+		return 0
+	}
+	return time.Duration(g.CrawlDelay * float64(time.Second))
+}
+
+// robotsMatch matches p against a pattern with * wildcards and an optional trailing $.
+func robotsMatch(pattern, p string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(p, parts[0]) {
+		return false
+	}
+	rest := p[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return !anchored || rest == ""
 }
 
+// CandidatePaths returns concrete paths worth requesting for every Allow and Disallow pattern,
+// e.g. /admin/*.php$ yields /admin/ and /admin/index.php.
+func (f *RobotsFile) CandidatePaths() []string {
+	if f == nil {
+		return nil
+	}
+	var out []string
+	seen := make(map[string]struct{})
+	for _, g := range f.Groups {
+		for _, rules := range [][]string{g.Disallow, g.Allow} {
+			for _, r := range rules {
+				for _, c := range ExpandRobotsPattern(r) {
+					if _, dup := seen[c]; !dup {
+						seen[c] = struct{}{}
+						out = append(out, c)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// webPageExts are expanded to index files; any other extension is expanded to backup files.
+var webPageExts = map[string]bool{".php": true, ".asp": true, ".aspx": true, ".jsp": true, ".html": true, ".htm": true, ".cgi": true}
+
+// ExpandRobotsPattern returns the candidate paths for one pattern; see CandidatePaths.
+func ExpandRobotsPattern(pattern string) []string {
+	p := strings.TrimSuffix(pattern, "$")
+	if !strings.HasPrefix(p, "/") {
+		return nil
+	}
+	star := strings.IndexByte(p, '*')
+	if star < 0 {
+		if p == "/" {
+			return nil
+		}
+		return []string{p}
+	}
+
+	var out []string
+	prefix := p[:star]
+	dir := prefix[:strings.LastIndexByte(prefix, '/')+1]
+	switch {
+	case strings.HasSuffix(prefix, "/") && len(prefix) > 1:
+		out = append(out, prefix)
+	case star == len(p)-1 && len(prefix) > 1:
+		// /backup* also matches /backup itself.
+		out = append(out, prefix)
+	case len(dir) > 1:
+		out = append(out, dir)
+	}
+
+	// /dir/*.ext: only a single wildcard, in the last segment, directly before the extension.
+	last := p[strings.LastIndexByte(p, '/')+1:]
+	if strings.Count(p, "*") == 1 && strings.HasPrefix(last, "*.") && !strings.ContainsAny(last[1:], "?/") {
+		ext := strings.ToLower(path.Ext(last))
+		if ext != "" && ext == strings.ToLower(last[1:]) {
+			name := "backup"
+			if webPageExts[ext] {
+				name = "index"
+			}
+			out = append(out, dir+name+last[1:])
+		}
+	}
+	return out
+}
+
+// fetchRobotsRules returns nil, which allows everything, when robots.txt is missing or unreadable.
+func fetchRobotsRules(parent context.Context, client *http.Client, base *url.URL, userAgent string, timeout time.Duration) *RobotsGroup {
+	f, err := FetchRobots(parent, client, base, userAgent, timeout, 512<<10)
+	if err != nil {
+		return nil
+	}
+	return f.Group(productToken(userAgent))
+}
+
+// productToken returns the robots.txt product token of a User-Agent, e.g. "wdf" for "wdf/1.2".
+func productToken(userAgent string) string {
+	ua := strings.TrimSpace(userAgent)
+	if i := strings.IndexAny(ua, " /"); i >= 0 {
+		ua = ua[:i]
+	}
+	return ua
+}
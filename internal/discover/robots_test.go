@@ -0,0 +1,182 @@
+package discover
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The path matching examples of RFC 9309 section 2.2.3 (and the matching Google documentation).
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{
+			pattern: "/fish",
+			match:   []string{"/fish", "/fish.html", "/fish/salmon.html", "/fishheads", "/fishheads/yummy.html", "/fish.php?id=anything"},
+			noMatch: []string{"/Fish.asp", "/catfish", "/?id=fish", "/desert/fish"},
+		},
+		{
+			pattern: "/fish*",
+			match:   []string{"/fish", "/fish.html", "/fish/salmon.html", "/fishheads", "/fishheads/yummy.html", "/fish.php?id=anything"},
+			noMatch: []string{"/Fish.asp", "/catfish", "/?id=fish", "/desert/fish"},
+		},
+		{
+			pattern: "/fish/",
+			match:   []string{"/fish/", "/fish/?id=anything", "/fish/salmon.htm"},
+			noMatch: []string{"/fish", "/fish.html", "/animals/fish/", "/Fish/Salmon.asp"},
+		},
+		{
+			pattern: "/*.php",
+			match:   []string{"/index.php", "/filename.php", "/folder/filename.php", "/folder/filename.php?parameters", "/folder/any.php.file.html", "/filename.php/"},
+			noMatch: []string{"/", "/windows.PHP"},
+		},
+		{
+			pattern: "/*.php$",
+			match:   []string{"/filename.php", "/folder/filename.php"},
+			noMatch: []string{"/filename.php?parameters", "/filename.php/", "/filename.php5", "/windows.PHP"},
+		},
+		{
+			pattern: "/fish*.php",
+			match:   []string{"/fish.php", "/fishheads/catfish.php?parameters"},
+			noMatch: []string{"/Fish.PHP"},
+		},
+		{
+			pattern: "*.gif$",
+			match:   []string{"/a.gif", "/images/b.gif"},
+			noMatch: []string{"/a.gif?x=1", "/a.giff"},
+		},
+	}
+	for _, tt := range tests {
+		for _, p := range tt.match {
+			if !robotsMatch(tt.pattern, p) {
+				t.Errorf("robotsMatch(%q, %q) = false, want true", tt.pattern, p)
+			}
+		}
+		for _, p := range tt.noMatch {
+			if robotsMatch(tt.pattern, p) {
+				t.Errorf("robotsMatch(%q, %q) = true, want false", tt.pattern, p)
+			}
+		}
+	}
+}
+
+// The precedence examples of RFC 9309 section 2.2.2: the longest match wins and Allow wins ties.
+func TestRobotsGroupAllowed(t *testing.T) {
+	tests := []struct {
+		allow, disallow string
+		path            string
+		want            bool
+	}{
+		{"/p", "/", "/page", true},
+		{"/folder", "/folder", "/folder/page", true},
+		{"/page", "/*.htm", "/page.htm", false},
+		{"/page", "/*.ph", "/page.php5", true},
+		{"/$", "/", "/", true},
+		{"/$", "/", "/page.htm", false},
+	}
+	for _, tt := range tests {
+		g := &RobotsGroup{Allow: []string{tt.allow}, Disallow: []string{tt.disallow}}
+		if got := g.Allowed(tt.path); got != tt.want {
+			t.Errorf("allow %q, disallow %q: Allowed(%q) = %v, want %v", tt.allow, tt.disallow, tt.path, got, tt.want)
+		}
+	}
+	var none *RobotsGroup
+	if !none.Allowed("/anything") {
+		t.Error("a nil group must allow everything")
+	}
+}
+
+// The example file of RFC 9309 section 5.1, plus a crawler named by two separate groups, which
+// section 2.2.1 says must be combined.
+const rfcRobots = "\ufeffUser-Agent: *\n" +
+	"Disallow: *.gif$\n" +
+	"Disallow: /example/\n" +
+	"Allow: /publications/\n" +
+	"\n" +
+	"User-Agent: foobot\n" +
+	"Disallow:/\n" +
+	"Allow:/example/page.html\n" +
+	"Allow:/example/allowed.gif\n" +
+	"\n" +
+	"User-Agent: barbot\n" +
+	"User-Agent: bazbot\n" +
+	"Disallow: /example/page.html\n" +
+	"\n" +
+	"User-Agent: ExampleBot # comment\n" +
+	"Disallow: /foo\n" +
+	"Crawl-delay: 2\n" +
+	"Sitemap: https://example.com/sitemap.xml\n" +
+	"\n" +
+	"user-agent: examplebot\n" +
+	"disallow: /baz\n" +
+	"crawl-delay: 5\n"
+
+func TestRobotsGroup(t *testing.T) {
+	f, err := ParseRobots(strings.NewReader(rfcRobots))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"https://example.com/sitemap.xml"}; !reflect.DeepEqual(f.Sitemaps, want) {
+		t.Errorf("sitemaps = %q, want %q", f.Sitemaps, want)
+	}
+
+	tests := []struct {
+		agent   string
+		allowed []string
+		blocked []string
+	}{
+		{agent: "foobot", allowed: []string{"/example/page.html", "/example/allowed.gif"}, blocked: []string{"/", "/publications/", "/example/other.html"}},
+		{agent: "FooBot", allowed: []string{"/example/page.html"}, blocked: []string{"/"}},
+		{agent: "barbot", allowed: []string{"/example/other.html", "/a.gif"}, blocked: []string{"/example/page.html"}},
+		{agent: "bazbot", blocked: []string{"/example/page.html"}},
+		{agent: "otherbot", allowed: []string{"/publications/a.html", "/"}, blocked: []string{"/example/page.html", "/images/a.gif"}},
+		{agent: "examplebot", allowed: []string{"/bar"}, blocked: []string{"/foo", "/baz/x"}},
+	}
+	for _, tt := range tests {
+		g := f.Group(tt.agent)
+		for _, p := range tt.allowed {
+			if !g.Allowed(p) {
+				t.Errorf("%s: %q blocked, want allowed", tt.agent, p)
+			}
+		}
+		for _, p := range tt.blocked {
+			if g.Allowed(p) {
+				t.Errorf("%s: %q allowed, want blocked", tt.agent, p)
+			}
+		}
+	}
+	if d := f.Group("examplebot").CrawlDelay; d != 5 {
+		t.Errorf("combined crawl delay = %v, want 5", d)
+	}
+}
+
+func TestExpandRobotsPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/admin", []string{"/admin"}},
+		{"/admin/$", []string{"/admin/"}},
+		{"/", nil},
+		{"*.gif$", nil},
+		{"/backup*", []string{"/backup"}},
+		{"/private/*", []string{"/private/"}},
+		{"/admin/*.php$", []string{"/admin/", "/admin/index.php"}},
+		{"/dumps/*.sql", []string{"/dumps/", "/dumps/backup.sql"}},
+		{"/*.bak$", []string{"/backup.bak"}},
+		{"/cgi-bin/*/x*.cgi", []string{"/cgi-bin/"}},
+		{"/search?*q=", nil},
+	}
+	for _, tt := range tests {
+		got := ExpandRobotsPattern(tt.pattern)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandRobotsPattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package discover
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/xml"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
@@ -10,7 +14,24 @@ import (
 	"time"
 )
 
-func FetchSitemaps(parent context.Context, client *http.Client, base *url.URL, seeds []string, userAgent string, timeout time.Duration, maxBytes int64, maxFetch int) ([]string, error) {
+// SitemapEntry is a URL advertised by a sitemap or feed; Sitemap is the file it came from.
+type SitemapEntry struct {
+	URL     string `json:"url"`
+	LastMod string `json:"lastmod,omitempty"`
+	Kind    string `json:"kind"`
+	Sitemap string `json:"sitemap"`
+}
+
+// SitemapStats counts the sitemap files requested; Errors omits 404s, the usual answer for a guess.
+type SitemapStats struct {
+	Fetched int      `json:"fetched"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// FetchSitemaps fetches the seed sitemaps and the same-origin sitemaps their indexes reference, and
+// returns every URL they list, cross-origin ones included.
+func FetchSitemaps(parent context.Context, client *http.Client, base *url.URL, seeds []string, userAgent string, timeout time.Duration, maxBytes int64, maxFetch int) ([]SitemapEntry, SitemapStats) {
 	if maxFetch <= 0 {
 		maxFetch = 50
 	}
@@ -32,93 +53,146 @@ func FetchSitemaps(parent context.Context, client *http.Client, base *url.URL, s
 		queue = append(queue, s)
 	}
 
-	found := make([]string, 0, 1024)
+	found := make([]SitemapEntry, 0, 1024)
 	seenURL := make(map[string]struct{}, 1024)
 
-	fetched := 0
-	for len(queue) > 0 && fetched < maxFetch {
+	var stats SitemapStats
+	for len(queue) > 0 && stats.Fetched+stats.Failed < maxFetch {
 		su := queue[0]
 		queue = queue[1:]
-		fetched++
 
-		ctx, cancel := context.WithTimeout(parent, timeout)
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, su, nil)
+		data, err := fetchSitemap(parent, client, su, userAgent, timeout, maxBytes)
 		if err != nil {
-			cancel()
+			stats.Failed++
+			var se *HTTPStatusError
+			if !errors.As(err, &se) || se.StatusCode != http.StatusNotFound {
+				stats.Errors = append(stats.Errors, err.Error())
+			}
 			continue
 		}
-		if userAgent != "" {
-			req.Header.Set("User-Agent", userAgent)
-		}
-		req.Header.Set("Accept", "application/xml,text/xml,*/*")
+		stats.Fetched++
+		children, entries := parseSitemap(data)
 
-		resp, err := client.Do(req)
+		parent, err := url.Parse(su)
 		if err != nil {
-			cancel()
-			continue
-		}
-
-		body := io.LimitReader(resp.Body, maxBytes)
-		root, locs := parseSitemapXML(body)
-		resp.Body.Close()
-		cancel()
-
-		if resp.StatusCode != http.StatusOK {
-			continue
+			parent = base
 		}
-
-		if strings.EqualFold(root, "sitemapindex") {
-			for _, loc := range locs {
-				loc = strings.TrimSpace(loc)
-				if loc == "" {
-					continue
-				}
-				u, err := url.Parse(loc)
-				if err != nil {
-					continue
-				}
-				if u.Host != "" && !strings.EqualFold(u.Host, base.Host) {
-					continue
-				}
-				if _, ok := seenSitemap[loc]; ok {
-					continue
-				}
-				seenSitemap[loc] = struct{}{}
-				queue = append(queue, loc)
+		for _, loc := range children {
+			ref, err := url.Parse(loc)
+			if err != nil {
+				continue
 			}
-			continue
-		}
-
-		for _, loc := range locs {
-			loc = strings.TrimSpace(loc)
-			if loc == "" {
+			// Child locations should be absolute, but relative ones resolve against their index.
+			u := parent.ResolveReference(ref)
+			u.Fragment = ""
+			if !strings.EqualFold(u.Host, base.Host) {
 				continue
 			}
-			u, err := url.Parse(loc)
-			if err != nil {
+			loc = u.String()
+			if _, ok := seenSitemap[loc]; ok {
 				continue
 			}
-			if u.Host != "" && !strings.EqualFold(u.Host, base.Host) {
+			seenSitemap[loc] = struct{}{}
+			queue = append(queue, loc)
+		}
+
+		for _, e := range entries {
+			u, err := url.Parse(e.URL)
+			if err != nil {
 				continue
 			}
 			u.Fragment = ""
-			u.RawQuery = ""
 			canon := u.String()
 			if _, ok := seenURL[canon]; ok {
 				continue
 			}
 			seenURL[canon] = struct{}{}
-			found = append(found, canon)
+			e.URL = canon
+			e.Sitemap = su
+			found = append(found, e)
+		}
+	}
+
+	return found, stats
+}
+
+// fetchSitemap decompresses bodies starting with the gzip magic number regardless of headers.
+func fetchSitemap(parent context.Context, client *http.Client, sitemapURL, userAgent string, timeout time.Duration, maxBytes int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	req.Header.Set("Accept", "application/xml,text/xml,application/rss+xml,application/atom+xml,text/plain,*/*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{URL: sitemapURL, StatusCode: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	if err != nil && len(data) == 0 {
+		return nil, err
+	}
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		// A truncated stream still yields the URLs before the cut.
+		data, _ = io.ReadAll(io.LimitReader(zr, maxBytes))
+	}
+	return data, nil
+}
+
+// parseSitemap returns the children of a sitemap index or the entries of any other sitemap or feed.
+func parseSitemap(data []byte) (children []string, entries []SitemapEntry) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		return nil, parseSitemapText(trimmed)
+	}
+	return parseSitemapXML(bytes.NewReader(trimmed))
+}
+
+func parseSitemapText(data []byte) []SitemapEntry {
+	var entries []SitemapEntry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+			entries = append(entries, SitemapEntry{URL: line, Kind: "page"})
 		}
 	}
+	return entries
+}
 
-	return found, nil
+// sitemapRecord collects one <url>, <sitemap>, RSS <item> or Atom <entry>.
+type sitemapRecord struct {
+	loc, lastmod, newsDate string
+	news                   bool
+	media                  []SitemapEntry
 }
 
-func parseSitemapXML(r io.Reader) (root string, locs []string) {
+func parseSitemapXML(r io.Reader) (children []string, entries []SitemapEntry) {
 	dec := xml.NewDecoder(r)
-	var inLoc bool
-	var b strings.Builder
+	dec.Strict = false
+
+	var (
+		root  string
+		stack []string
+		cur   *sitemapRecord
+		b     strings.Builder
+	)
 	for {
 		tok, err := dec.Token()
 		if err != nil {
@@ -126,25 +200,116 @@ func parseSitemapXML(r io.Reader) (root string, locs []string) {
 		}
 		switch t := tok.(type) {
 		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
 			if root == "" {
-				root = t.Name.Local
+				root = name
 			}
-			if strings.EqualFold(t.Name.Local, "loc") {
-				inLoc = true
-				b.Reset()
+			stack = append(stack, name)
+			b.Reset()
+			switch {
+			case name == "url" || name == "sitemap" || name == "item" || name == "entry":
+				cur = &sitemapRecord{}
+			case name == "link" && root == "feed" && cur != nil:
+				// Atom links carry the URL in href; rel defaults to alternate.
+				var href, rel string
+				for _, a := range t.Attr {
+					switch strings.ToLower(a.Name.Local) {
+					case "href":
+						href = a.Value
+					case "rel":
+						rel = a.Value
+					}
+				}
+				if cur.loc == "" && href != "" && (rel == "" || rel == "alternate") {
+					cur.loc = strings.TrimSpace(href)
+				}
 			}
 		case xml.CharData:
-			if inLoc {
-				b.Write([]byte(t))
-			}
+			b.Write(t)
 		case xml.EndElement:
-			if inLoc && strings.EqualFold(t.Name.Local, "loc") {
-				inLoc = false
-				locs = append(locs, strings.TrimSpace(b.String()))
-				b.Reset()
+			if len(stack) == 0 {
+				break
+			}
+			name := stack[len(stack)-1]
+			parent := ""
+			if len(stack) > 1 {
+				parent = stack[len(stack)-2]
+			}
+			stack = stack[:len(stack)-1]
+			text := strings.TrimSpace(b.String())
+			b.Reset()
+			if cur == nil {
+				break
+			}
+
+			switch {
+			case name == "loc" && (parent == "url" || parent == "sitemap"):
+				cur.loc = text
+			case name == "link" && parent == "item" && text != "":
+				cur.loc = text
+			case name == "loc" && parent == "image":
+				cur.media = append(cur.media, SitemapEntry{URL: text, Kind: "image"})
+			case (name == "content_loc" || name == "player_loc" || name == "thumbnail_loc") && parent == "video":
+				cur.media = append(cur.media, SitemapEntry{URL: text, Kind: "video"})
+			case name == "news":
+				cur.news = true
+			case name == "publication_date" && parent == "news":
+				cur.newsDate = text
+			case name == "lastmod", name == "pubdate" && parent == "item", name == "updated" && parent == "entry":
+				cur.lastmod = text
+			case name == "published" && parent == "entry" && cur.lastmod == "":
+				cur.lastmod = text
+
+			case name == "sitemap" && root == "sitemapindex":
+				if cur.loc != "" {
+					children = append(children, cur.loc)
+				}
+				cur = nil
+			case name == "url" || name == "item" || name == "entry":
+				entries = append(entries, cur.entries(root)...)
+				cur = nil
 			}
 		}
 	}
-	return root, locs
+	return children, entries
 }
 
+func (r *sitemapRecord) entries(root string) []SitemapEntry {
+	lastmod := r.lastmod
+	if lastmod == "" {
+		lastmod = r.newsDate
+	}
+	lastmod = normalizeLastMod(lastmod)
+
+	var out []SitemapEntry
+	if r.loc != "" {
+		kind := "page"
+		switch {
+		case root == "rss" || root == "feed":
+			kind = "feed"
+		case r.news:
+			kind = "news"
+		}
+		out = append(out, SitemapEntry{URL: r.loc, LastMod: lastmod, Kind: kind})
+	}
+	for _, m := range r.media {
+		if m.URL != "" {
+			m.LastMod = lastmod
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+var lastModLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02", time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"}
+
+// normalizeLastMod converts W3C datetimes and RSS dates to RFC 3339; unparseable values are kept.
+func normalizeLastMod(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range lastModLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return s
+}
@@ -0,0 +1,196 @@
+package discover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseSitemap(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		children []string
+		entries  []SitemapEntry
+	}{
+		{
+			name: "urlset",
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://a.example/ </loc><lastmod>2024-03-01</lastmod></url>
+  <url><loc>https://a.example/admin/</loc></url>
+</urlset>`,
+			entries: []SitemapEntry{
+				{URL: "https://a.example/", LastMod: "2024-03-01T00:00:00Z", Kind: "page"},
+				{URL: "https://a.example/admin/", Kind: "page"},
+			},
+		},
+		{
+			name:    "byte order mark and leading space",
+			data:    "\ufeff\n  <urlset><url><loc>https://a.example/bom</loc></url></urlset>",
+			entries: []SitemapEntry{{URL: "https://a.example/bom", Kind: "page"}},
+		},
+		{
+			name: "sitemap index",
+			data: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://a.example/sitemap-posts.xml</loc><lastmod>2024-01-01</lastmod></sitemap>
+  <sitemap><loc>sitemap-pages.xml.gz</loc></sitemap>
+</sitemapindex>`,
+			children: []string{"https://a.example/sitemap-posts.xml", "sitemap-pages.xml.gz"},
+		},
+		{
+			name: "image, video and news extensions",
+			data: `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"
+  xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"
+  xmlns:video="http://www.google.com/schemas/sitemap-video/1.1"
+  xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
+  <url>
+    <loc>https://a.example/gallery</loc>
+    <image:image><image:loc>https://a.example/img/1.jpg</image:loc></image:image>
+    <video:video><video:thumbnail_loc>https://a.example/t.jpg</video:thumbnail_loc><video:content_loc>https://a.example/v.mp4</video:content_loc></video:video>
+  </url>
+  <url>
+    <loc>https://a.example/news/launch</loc>
+    <news:news><news:publication><news:name>A</news:name></news:publication><news:publication_date>2024-05-06T07:08:09+02:00</news:publication_date></news:news>
+  </url>
+</urlset>`,
+			entries: []SitemapEntry{
+				{URL: "https://a.example/gallery", Kind: "page"},
+				{URL: "https://a.example/img/1.jpg", Kind: "image"},
+				{URL: "https://a.example/t.jpg", Kind: "video"},
+				{URL: "https://a.example/v.mp4", Kind: "video"},
+				{URL: "https://a.example/news/launch", LastMod: "2024-05-06T05:08:09Z", Kind: "news"},
+			},
+		},
+		{
+			name: "rss",
+			data: `<rss version="2.0"><channel><title>Blog</title><link>https://a.example/</link>
+  <item><title>One</title><link>https://a.example/posts/1</link><pubDate>Tue, 02 Jan 2024 15:04:05 GMT</pubDate></item>
+  <item><title>Two</title><link>https://a.example/posts/2</link><pubDate>Wed, 3 Jan 2024 10:00:00 +0100</pubDate></item>
+</channel></rss>`,
+			entries: []SitemapEntry{
+				{URL: "https://a.example/posts/1", LastMod: "2024-01-02T15:04:05Z", Kind: "feed"},
+				{URL: "https://a.example/posts/2", LastMod: "2024-01-03T09:00:00Z", Kind: "feed"},
+			},
+		},
+		{
+			name: "atom",
+			data: `<feed xmlns="http://www.w3.org/2005/Atom"><link rel="self" href="https://a.example/feed.atom"/>
+  <entry><link rel="edit" href="https://a.example/api/1"/><link href="https://a.example/posts/1"/><updated>2024-02-03T04:05:06Z</updated></entry>
+  <entry><link rel="alternate" href="https://a.example/posts/2"/><published>2024-02-04T00:00:00Z</published></entry>
+</feed>`,
+			entries: []SitemapEntry{
+				{URL: "https://a.example/posts/1", LastMod: "2024-02-03T04:05:06Z", Kind: "feed"},
+				{URL: "https://a.example/posts/2", LastMod: "2024-02-04T00:00:00Z", Kind: "feed"},
+			},
+		},
+		{
+			name:    "plain text",
+			data:    "https://a.example/one\n# comment\n\nhttps://a.example/two\r\nftp://a.example/no\n",
+			entries: []SitemapEntry{{URL: "https://a.example/one", Kind: "page"}, {URL: "https://a.example/two", Kind: "page"}},
+		},
+		{
+			name:    "truncated xml keeps complete records",
+			data:    `<urlset><url><loc>https://a.example/a</loc></url><url><loc>https://a.example/b`,
+			entries: []SitemapEntry{{URL: "https://a.example/a", Kind: "page"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			children, entries := parseSitemap([]byte(tt.data))
+			if !reflect.DeepEqual(children, tt.children) {
+				t.Errorf("children = %q, want %q", children, tt.children)
+			}
+			if !reflect.DeepEqual(entries, tt.entries) {
+				t.Errorf("entries = %+v\nwant %+v", entries, tt.entries)
+			}
+		})
+	}
+}
+
+func TestNormalizeLastMod(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-03-01", "2024-03-01T00:00:00Z"},
+		{"2024-03-01T10:20:30Z", "2024-03-01T10:20:30Z"},
+		{"2024-03-01T10:20:30+02:00", "2024-03-01T08:20:30Z"},
+		{"2024-03-01T10:20+01:00", "2024-03-01T09:20:00Z"},
+		{"Fri, 01 Mar 2024 10:20:30 +0000", "2024-03-01T10:20:30Z"},
+		{"Fri, 01 Mar 2024 10:20:30 GMT", "2024-03-01T10:20:30Z"},
+		{"Fri, 1 Mar 2024 10:20:30 -0500", "2024-03-01T15:20:30Z"},
+		{" 2024-03-01 ", "2024-03-01T00:00:00Z"},
+		{"yesterday", "yesterday"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeLastMod(tt.in); got != tt.want {
+			t.Errorf("normalizeLastMod(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFetchSitemaps(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemaps/index.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<sitemapindex>
+  <sitemap><loc>pages.xml.gz</loc></sitemap>
+  <sitemap><loc>/sitemaps/../posts.xml</loc></sitemap>
+  <sitemap><loc>https://elsewhere.example/sitemap.xml</loc></sitemap>
+  <sitemap><loc>missing.xml</loc></sitemap>
+  <sitemap><loc>broken.xml</loc></sitemap>
+</sitemapindex>`))
+	})
+	mux.HandleFunc("/sitemaps/pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		// Served as an opaque download; the gzip magic number is what counts.
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(gzipBytes(t, `<urlset><url><loc>https://a.example/about</loc></url></urlset>`))
+	})
+	mux.HandleFunc("/posts.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<urlset><url><loc>https://a.example/posts/1#comments</loc></url><url><loc>https://a.example/about</loc></url></urlset>`))
+	})
+	mux.HandleFunc("/sitemaps/broken.xml", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	base, _ := url.Parse(srv.URL)
+
+	seeds := []string{srv.URL + "/sitemaps/index.xml", srv.URL + "/sitemaps/index.xml"}
+	entries, stats := FetchSitemaps(context.Background(), srv.Client(), base, seeds, "wdf-test", 5*time.Second, 0, 10)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.URL+" <- "+e.Sitemap[len(srv.URL):])
+	}
+	sort.Strings(got)
+	want := []string{
+		"https://a.example/about <- /sitemaps/pages.xml.gz",
+		"https://a.example/posts/1 <- /posts.xml",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %q\nwant %q", got, want)
+	}
+	// The 404 counts as failed but is not reported; the cross-origin child is never requested.
+	if stats.Fetched != 3 || stats.Failed != 2 || len(stats.Errors) != 1 {
+		t.Errorf("stats = %+v, want 3 fetched, 2 failed, 1 error", stats)
+	}
+}
@@ -0,0 +1,332 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ActuatorExposure describes the Spring Boot Actuator endpoints exposed by a target.
+type ActuatorExposure struct {
+	BasePath  string             `json:"base_path"`
+	Legacy    bool               `json:"legacy,omitempty"`
+	Endpoints []ActuatorEndpoint `json:"endpoints,omitempty"`
+	Env       *ActuatorEnv       `json:"env,omitempty"`
+}
+
+type ActuatorEndpoint struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Risk       Severity `json:"risk"`
+	Reason     string   `json:"reason,omitempty"`
+	StatusCode int      `json:"status_code,omitempty"`
+}
+
+// ActuatorEnv records whether env masks values; UnmaskedKeys names secret-looking keys served in clear.
+type ActuatorEnv struct {
+	Properties   int      `json:"properties"`
+	Masked       bool     `json:"masked"`
+	UnmaskedKeys []string `json:"unmasked_keys,omitempty"`
+}
+
+type actuatorRisk struct {
+	severity Severity
+	reason   string
+}
+
+var actuatorRisks = map[string]actuatorRisk{
+	"env":            {SeverityHigh, "environment and configuration properties"},
+	"configprops":    {SeverityHigh, "configuration properties"},
+	"heapdump":       {SeverityHigh, "JVM heap dump with in-memory secrets"},
+	"jolokia":        {SeverityHigh, "JMX over HTTP"},
+	"gateway":        {SeverityHigh, "Spring Cloud Gateway route management"},
+	"trace":          {SeverityHigh, "recent requests including headers"},
+	"httptrace":      {SeverityHigh, "recent requests including headers"},
+	"httpexchanges":  {SeverityHigh, "recent requests including headers"},
+	"sessions":       {SeverityHigh, "user sessions"},
+	"shutdown":       {SeverityHigh, "application shutdown"},
+	"restart":        {SeverityHigh, "application restart"},
+	"threaddump":     {SeverityMedium, "thread dump"},
+	"dump":           {SeverityMedium, "thread dump"},
+	"loggers":        {SeverityMedium, "runtime log level changes"},
+	"logfile":        {SeverityMedium, "application log file"},
+	"mappings":       {SeverityMedium, "request mappings"},
+	"auditevents":    {SeverityMedium, "audit events"},
+	"refresh":        {SeverityMedium, "configuration refresh"},
+	"beans":          {SeverityLow, "bean definitions"},
+	"conditions":     {SeverityLow, "auto-configuration report"},
+	"metrics":        {SeverityLow, "application metrics"},
+	"prometheus":     {SeverityLow, "application metrics"},
+	"scheduledtasks": {SeverityLow, "scheduled tasks"},
+	"caches":         {SeverityLow, "cache names"},
+	"info":           {SeverityLow, "build information"},
+	"health":         {SeverityLow, "health status"},
+}
+
+// Boot 1.x endpoints at the application root, probed once a legacy env endpoint is confirmed.
+var legacyActuatorEndpoints = []string{"heapdump", "trace", "dump", "mappings", "configprops", "beans", "jolokia", "loggers", "logfile", "auditevents"}
+
+var (
+	actuatorMaskRe = regexp.MustCompile(`^\*+$`)
+)
+
+func isActuatorIndex(snippet string) bool {
+	s := strings.TrimSpace(snippet)
+	return strings.HasPrefix(s, "{") && strings.Contains(s, `"_links"`)
+}
+
+// isActuatorEnvCandidate avoids refetching every page named env in full.
+func isActuatorEnvCandidate(p string, headers map[string][]string, snippet string) bool {
+	if path.Base(p) != "env" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(firstHeader(headers, "Content-Type")), "json") {
+		return true
+	}
+	return strings.Contains(snippet, `"propertySources"`) || strings.Contains(snippet, `"activeProfiles"`) || strings.Contains(snippet, `"profiles"`)
+}
+
+// inspectActuatorIndex takes the base path from the index's self link to handle custom base paths.
+func inspectActuatorIndex(ctx context.Context, client *http.Client, cfg Config, j job, indexURL string) *ActuatorExposure {
+	status, _, body, err := fetchRaw(ctx, client, cfg, indexURL, map[string]string{"Accept": "application/json"}, 256<<10)
+	if err != nil || status != http.StatusOK {
+		return nil
+	}
+	var idx struct {
+		Links map[string]struct {
+			Href      string `json:"href"`
+			Templated bool   `json:"templated"`
+		} `json:"_links"`
+	}
+	if json.Unmarshal(body, &idx) != nil || len(idx.Links) == 0 {
+		return nil
+	}
+	// Plenty of HAL APIs have _links; require the well-known health or info endpoints.
+	if _, ok := idx.Links["health"]; !ok {
+		if _, ok := idx.Links["info"]; !ok {
+			return nil
+		}
+	}
+
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return nil
+	}
+	if self, ok := idx.Links["self"]; ok {
+		if u, err := url.Parse(self.Href); err == nil && (u.Host == "" || strings.EqualFold(u.Host, base.Host)) {
+			base.Path = u.Path
+		}
+	}
+	if !j.state.claim("actuator:" + strings.TrimSuffix(base.Path, "/")) {
+		return nil
+	}
+
+	x := &ActuatorExposure{BasePath: base.Path}
+	for name, l := range idx.Links {
+		if name == "self" || l.Templated {
+			continue
+		}
+		// Links like "caches-cache" or "metrics-requiredMetricName" describe sub-resources.
+		key := strings.ToLower(name)
+		if _, ok := actuatorRisks[key]; !ok {
+			if i := strings.IndexByte(key, '-'); i > 0 {
+				if _, ok := actuatorRisks[key[:i]]; ok {
+					continue
+				}
+			}
+		}
+		r, ok := actuatorRisks[key]
+		if !ok {
+			r = actuatorRisk{SeverityLow, "custom endpoint"}
+		}
+		x.Endpoints = append(x.Endpoints, ActuatorEndpoint{Name: name, URL: l.Href, Risk: r.severity, Reason: r.reason})
+	}
+	sort.Slice(x.Endpoints, func(a, b int) bool { return x.Endpoints[a].Name < x.Endpoints[b].Name })
+
+	for _, e := range x.Endpoints {
+		if e.Name == "env" {
+			x.Env = fetchActuatorEnv(ctx, client, cfg, resolveSameHost(base, e.URL))
+			break
+		}
+	}
+	return x
+}
+
+// inspectLegacyActuator probes the endpoints next to a confirmed env outside the /actuator layout.
+// Only the first few kilobytes are read, so heap dumps are never downloaded.
+func inspectLegacyActuator(ctx context.Context, client *http.Client, cfg Config, j job, envURL string) *ActuatorExposure {
+	env := fetchActuatorEnv(ctx, client, cfg, envURL)
+	if env == nil {
+		return nil
+	}
+	base, err := url.Parse(envURL)
+	if err != nil {
+		return nil
+	}
+	base.Path = path.Dir(base.Path)
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	x := &ActuatorExposure{BasePath: base.Path, Env: env}
+	if strings.Contains(base.Path, "/actuator") || !j.state.claim("actuator:"+strings.TrimSuffix(base.Path, "/")) {
+		// The default layout is covered by the index inspection; only report env masking here.
+		return x
+	}
+	x.Legacy = base.Path == "/"
+
+	r := actuatorRisks["env"]
+	x.Endpoints = append(x.Endpoints, ActuatorEndpoint{Name: "env", URL: envURL, Risk: r.severity, Reason: r.reason, StatusCode: http.StatusOK})
+	for _, name := range legacyActuatorEndpoints {
+		u := base.ResolveReference(&url.URL{Path: name}).String()
+		st, hdr, b, err := fetchRaw(ctx, client, cfg, u, map[string]string{"Accept": "application/json,application/octet-stream"}, 4<<10)
+		if err != nil || st != http.StatusOK || !looksLikeActuatorResponse(name, hdr, b) {
+			continue
+		}
+		r := actuatorRisks[name]
+		x.Endpoints = append(x.Endpoints, ActuatorEndpoint{Name: name, URL: u, Risk: r.severity, Reason: r.reason, StatusCode: st})
+	}
+	return x
+}
+
+// looksLikeActuatorResponse filters catch-all pages: endpoints answer JSON, or binary/plain for heapdump and logfile.
+func looksLikeActuatorResponse(name string, hdr http.Header, b []byte) bool {
+	ct := strings.ToLower(hdr.Get("Content-Type"))
+	switch name {
+	case "heapdump":
+		return strings.Contains(ct, "octet-stream") || strings.HasPrefix(string(b), "JAVA PROFILE")
+	case "logfile":
+		return strings.HasPrefix(ct, "text/plain")
+	}
+	s := strings.TrimSpace(string(b))
+	return strings.Contains(ct, "json") && (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "["))
+}
+
+// fetchActuatorEnv loads an env endpoint (Boot 1.x or 2+ layout) and reports masking.
+func fetchActuatorEnv(ctx context.Context, client *http.Client, cfg Config, envURL string) *ActuatorEnv {
+	if envURL == "" {
+		return nil
+	}
+	st, _, b, err := fetchRaw(ctx, client, cfg, envURL, map[string]string{"Accept": "application/json"}, 2<<20)
+	if err != nil || st != http.StatusOK {
+		return nil
+	}
+	var doc map[string]any
+	if json.Unmarshal(b, &doc) != nil {
+		return nil
+	}
+	_, boot2 := doc["propertySources"]
+	_, boot1 := doc["profiles"]
+	if !boot2 && !boot1 {
+		return nil
+	}
+
+	env := &ActuatorEnv{}
+	var total, masked int
+	unmasked := make(map[string]struct{})
+	visit := func(key string, v any) {
+		s, ok := v.(string)
+		if !ok {
+			return
+		}
+		env.Properties++
+		isMasked := actuatorMaskRe.MatchString(s)
+		if secretNameRe.MatchString(key) {
+			total++
+			if isMasked {
+				masked++
+			} else if s != "" {
+				unmasked[key] = struct{}{}
+			}
+		}
+	}
+
+	if boot2 {
+		sources, _ := doc["propertySources"].([]any)
+		for _, src := range sources {
+			m, _ := src.(map[string]any)
+			props, _ := m["properties"].(map[string]any)
+			for k, v := range props {
+				if pv, ok := v.(map[string]any); ok {
+					visit(k, pv["value"])
+				}
+			}
+		}
+	} else {
+		for name, section := range doc {
+			if name == "profiles" {
+				continue
+			}
+			if props, ok := section.(map[string]any); ok {
+				for k, v := range props {
+					visit(k, v)
+				}
+			}
+		}
+	}
+
+	env.Masked = len(unmasked) == 0 && (total == 0 || masked == total)
+	env.UnmaskedKeys = sortedKeys(unmasked)
+	return env
+}
+
+// applyActuatorEvidence rates env as high risk only when secret-looking values are unmasked.
+func applyActuatorEvidence(x *ActuatorExposure, a *Analysis, flags analysisFlags) {
+	a.Interesting = true
+	best := SeverityLow
+	var risky []string
+	for _, e := range x.Endpoints {
+		risk := e.Risk
+		if (e.Name == "env" || e.Name == "configprops") && x.Env != nil && x.Env.Masked {
+			risk = SeverityMedium
+		}
+		if risk == SeverityHigh {
+			risky = append(risky, e.Name)
+		}
+		if severityRank(risk) > severityRank(best) {
+			best = risk
+		}
+	}
+	if len(x.Endpoints) > 0 {
+		a.Reasons = append(a.Reasons, "actuator endpoints exposed under "+x.BasePath)
+	}
+	if len(risky) > 0 {
+		a.Reasons = append(a.Reasons, "high-risk actuator endpoints: "+joinLimited(risky, 5))
+	}
+
+	if x.Env != nil {
+		switch {
+		case len(x.Env.UnmaskedKeys) > 0:
+			best = SeverityHigh
+			a.Reasons = append(a.Reasons, "actuator env serves unmasked secrets: "+joinLimited(x.Env.UnmaskedKeys, 5))
+		case x.Env.Masked:
+			a.Reasons = append(a.Reasons, "actuator env values are masked")
+			if len(x.Endpoints) == 0 && !flags.ConfirmedSecret && a.Severity == SeverityHigh {
+				// This result is the env endpoint itself; masking lowers its impact.
+				a.Severity = SeverityMedium
+				a.Reasons = append(a.Reasons, "severity lowered because env values are masked")
+				return
+			}
+		}
+	}
+
+	if severityRank(best) > severityRank(a.Severity) {
+		a.Severity = best
+	}
+}
+
+func resolveSameHost(base *url.URL, ref string) string {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	u := base.ResolveReference(r)
+	if !strings.EqualFold(u.Host, base.Host) {
+		return ""
+	}
+	return u.String()
+}
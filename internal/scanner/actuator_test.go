@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsActuatorEnvCandidate(t *testing.T) {
+	jsonCT := map[string][]string{"Content-Type": {"application/vnd.spring-boot.actuator.v3+json"}}
+	htmlCT := map[string][]string{"Content-Type": {"text/html"}}
+	tests := []struct {
+		path    string
+		headers map[string][]string
+		snippet string
+		want    bool
+	}{
+		{"/env", jsonCT, "{", true},
+		{"/manage/env", htmlCT, `{"activeProfiles":["prod"],"propertySources":[`, true},
+		{"/env", nil, `{"profiles":[],"server.ports":{}}`, true},
+		{"/env", htmlCT, "<html>Environment settings</html>", false},
+		{"/environment", jsonCT, "{", false},
+	}
+	for _, tt := range tests {
+		if got := isActuatorEnvCandidate(tt.path, tt.headers, tt.snippet); got != tt.want {
+			t.Errorf("isActuatorEnvCandidate(%q, %v, %q) = %v, want %v", tt.path, tt.headers, tt.snippet, got, tt.want)
+		}
+	}
+}
+
+func TestFetchActuatorEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *ActuatorEnv
+	}{
+		{
+			name: "boot 2 masked",
+			body: `{"activeProfiles": ["prod"], "propertySources": [{"name": "application.yml", "properties": {
+				"spring.datasource.password": {"value": "******"}, "server.port": {"value": "8080"}, "timeout": {"value": 30}}}]}`,
+			want: &ActuatorEnv{Properties: 2, Masked: true},
+		},
+		{
+			name: "boot 2 unmasked",
+			body: `{"activeProfiles": [], "propertySources": [{"name": "systemEnvironment", "properties": {
+				"DB_PASSWORD": {"value": "hunter2"}, "API_TOKEN": {"value": "******"}, "EMPTY_SECRET": {"value": ""}}}]}`,
+			want: &ActuatorEnv{Properties: 3, UnmaskedKeys: []string{"DB_PASSWORD"}},
+		},
+		{
+			name: "boot 1",
+			body: `{"profiles": [], "applicationConfig: [classpath:/application.properties]": {"jwt.secret": "s3cr3t", "app.name": "shop"}}`,
+			want: &ActuatorEnv{Properties: 2, UnmaskedKeys: []string{"jwt.secret"}},
+		},
+		{name: "other json", body: `{"status": "UP"}`},
+		{name: "html", body: `<html><body>env</body></html>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got := fetchActuatorEnv(context.Background(), srv.Client(), Config{Timeout: 5 * time.Second}, srv.URL+"/env")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("env = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectActuatorIndex(t *testing.T) {
+	mux := http.NewServeMux()
+	// A custom management base path, advertised through the self link.
+	mux.HandleFunc("/manage", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_links": {
+			"self": {"href": "http://` + r.Host + `/manage", "templated": false},
+			"health": {"href": "http://` + r.Host + `/manage/health", "templated": false},
+			"health-path": {"href": "http://` + r.Host + `/manage/health/{*path}", "templated": true},
+			"caches-cache": {"href": "http://` + r.Host + `/manage/caches/{cache}", "templated": true},
+			"caches": {"href": "http://` + r.Host + `/manage/caches", "templated": false},
+			"env": {"href": "http://` + r.Host + `/manage/env", "templated": false},
+			"custom-probe": {"href": "http://` + r.Host + `/manage/custom-probe", "templated": false}}}`))
+	})
+	mux.HandleFunc("/manage/env", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"activeProfiles": [], "propertySources": [{"name": "env", "properties": {"AWS_SECRET_ACCESS_KEY": {"value": "abc"}}}]}`))
+	})
+	mux.HandleFunc("/hal", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_links": {"self": {"href": "/hal"}, "orders": {"href": "/orders"}}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := Config{Timeout: 5 * time.Second}
+	j := job{state: newTargetState()}
+	x := inspectActuatorIndex(context.Background(), srv.Client(), cfg, j, srv.URL+"/manage")
+	if x == nil {
+		t.Fatal("actuator index not recognised")
+	}
+	if x.BasePath != "/manage" {
+		t.Errorf("base path = %q, want /manage", x.BasePath)
+	}
+	var names []string
+	for _, e := range x.Endpoints {
+		names = append(names, e.Name)
+	}
+	if want := []string{"caches", "custom-probe", "env", "health"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("endpoints = %q, want %q", names, want)
+	}
+	if x.Env == nil || x.Env.Masked || !reflect.DeepEqual(x.Env.UnmaskedKeys, []string{"AWS_SECRET_ACCESS_KEY"}) {
+		t.Errorf("env = %+v", x.Env)
+	}
+
+	var a Analysis
+	applyActuatorEvidence(x, &a, analysisFlags{})
+	if a.Severity != SeverityHigh {
+		t.Errorf("severity = %s, want high", a.Severity)
+	}
+
+	// The same base path is not inspected twice, and a generic HAL API is not an actuator.
+	if x := inspectActuatorIndex(context.Background(), srv.Client(), cfg, j, srv.URL+"/manage"); x != nil {
+		t.Errorf("second inspection = %+v, want nil", x)
+	}
+	if x := inspectActuatorIndex(context.Background(), srv.Client(), cfg, j, srv.URL+"/hal"); x != nil {
+		t.Errorf("HAL API = %+v, want nil", x)
+	}
+}
+
+func TestInspectLegacyActuator(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/env", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"profiles": [], "systemProperties": {"java.version": "1.8.0"}}`))
+	})
+	mux.HandleFunc("/beans", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"context": "application", "beans": []}]`))
+	})
+	mux.HandleFunc("/heapdump", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("JAVA PROFILE 1.0.2\x00"))
+	})
+	// Everything else is a catch-all HTML page.
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>app</html>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := Config{Timeout: 5 * time.Second}
+	x := inspectLegacyActuator(context.Background(), srv.Client(), cfg, job{state: newTargetState()}, srv.URL+"/env")
+	if x == nil {
+		t.Fatal("legacy actuator not recognised")
+	}
+	if !x.Legacy || x.BasePath != "/" {
+		t.Errorf("legacy = %v, base path = %q", x.Legacy, x.BasePath)
+	}
+	var names []string
+	for _, e := range x.Endpoints {
+		names = append(names, e.Name)
+	}
+	if want := []string{"env", "heapdump", "beans"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("endpoints = %q, want %q", names, want)
+	}
+	if x.Env == nil || !x.Env.Masked {
+		t.Errorf("env = %+v, want masked", x.Env)
+	}
+
+	if x := inspectLegacyActuator(context.Background(), srv.Client(), cfg, job{state: newTargetState()}, srv.URL+"/admin/env"); x != nil {
+		t.Errorf("catch-all env page = %+v, want nil", x)
+	}
+}
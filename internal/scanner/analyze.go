@@ -6,15 +6,18 @@ import (
 	"strings"
 )
 
-var (
-	metaRobotsRe = regexp.MustCompile(`(?is)<meta[^>]+name\s*=\s*["']robots["'][^>]*>`)
-	contentAttr  = regexp.MustCompile(`(?is)\bcontent\s*=\s*["']([^"']+)["']`)
-)
+var contentAttr = regexp.MustCompile(`(?is)\bcontent\s*=\s*["']([^"']+)["']`)
 
 type analysisFlags struct {
 	NoIndex         bool
 	DirectoryListing bool
 	ConfirmedSecret bool
+	Observability   bool
+
+	GraphQLIntrospection bool
+	SourceMap            bool
+	FileParameter        bool
+	RobotsLeak           bool
 }
 
 func analyze(path string, status int, headers map[string][]string, snippet string, rs RuleSet, isSensitive bool, critical bool) (Analysis, analysisFlags) {
@@ -68,6 +71,12 @@ func analyze(path string, status int, headers map[string][]string, snippet strin
 		}
 	}
 
+	if names := fileLikeParams(path); len(names) > 0 {
+		flags.FileParameter = true
+		a.Interesting = true
+		reasons = append(reasons, "query parameter takes a file name or path: "+strings.Join(names, ", "))
+	}
+
 	if headers != nil {
 		if v := firstHeader(headers, "Content-Disposition"); v != "" && strings.Contains(strings.ToLower(v), "attachment") {
 			if a.Severity == SeverityLow {
@@ -76,27 +85,6 @@ func analyze(path string, status int, headers map[string][]string, snippet strin
 			a.Interesting = true
 			reasons = append(reasons, "downloadable attachment response")
 		}
-		if x := firstHeader(headers, "X-Robots-Tag"); hasNoIndexDirective(x) {
-			flags.NoIndex = true
-			reasons = append(reasons, "X-Robots-Tag indicates noindex")
-		}
-	}
-
-	if hasMetaNoIndex(snippet) {
-		flags.NoIndex = true
-		reasons = append(reasons, "meta robots indicates noindex")
-	}
-
-	// Downgrade one level if explicitly noindex, but never downgrade confirmed secrets or directory listing.
-	if flags.NoIndex && !flags.ConfirmedSecret && !flags.DirectoryListing {
-		switch a.Severity {
-		case SeverityHigh:
-			a.Severity = SeverityMedium
-			reasons = append(reasons, "severity downgraded due to explicit noindex")
-		case SeverityMedium:
-			a.Severity = SeverityLow
-			reasons = append(reasons, "severity downgraded due to explicit noindex")
-		}
 	}
 
 	a.Reasons = dedupeStrings(reasons)
@@ -124,21 +112,6 @@ func hasNoIndexDirective(v string) bool {
 	return false
 }
 
-func hasMetaNoIndex(html string) bool {
-	if html == "" {
-		return false
-	}
-	m := metaRobotsRe.FindString(html)
-	if m == "" {
-		return false
-	}
-	cm := contentAttr.FindStringSubmatch(m)
-	if len(cm) < 2 {
-		return false
-	}
-	return hasNoIndexDirective(cm[1])
-}
-
 func firstHeader(h map[string][]string, key string) string {
 	for k, v := range h {
 		if strings.EqualFold(k, key) && len(v) > 0 {
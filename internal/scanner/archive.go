@@ -0,0 +1,328 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ArchiveListing describes the contents of an exposed archive as read from its index alone.
+type ArchiveListing struct {
+	Format           string         `json:"format"`
+	Size             int64          `json:"size,omitempty"`
+	TotalEntries     int            `json:"total_entries"`
+	Entries          []ArchiveEntry `json:"entries,omitempty"`
+	SensitiveEntries []string       `json:"sensitive_entries,omitempty"`
+	BytesRead        int64          `json:"bytes_read"`
+	Truncated        bool           `json:"truncated,omitempty"`
+	Error            string         `json:"error,omitempty"`
+}
+
+type ArchiveEntry struct {
+	Name           string `json:"name"`
+	Size           uint64 `json:"size"`
+	CompressedSize uint64 `json:"compressed_size"`
+	Sensitive      bool   `json:"sensitive,omitempty"`
+}
+
+const (
+	zipEOCDSig        = 0x06054b50
+	zipEOCD64Sig      = 0x06064b50
+	zipEOCD64LocSig   = 0x07064b50
+	zipCentralDirSig  = 0x02014b50
+	zipEOCDLen        = 22
+	zipEOCD64Len      = 56
+	zipEOCD64LocLen   = 20
+	zipCentralHdrLen  = 46
+	zipMaxCommentLen  = 0xFFFF
+	maxArchiveEntries = 500
+)
+
+func isZipResponse(p string, headers map[string][]string) bool {
+	if strings.EqualFold(path.Ext(p), ".zip") {
+		return true
+	}
+	ct := strings.ToLower(firstHeader(headers, "Content-Type"))
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	switch strings.TrimSpace(ct) {
+	case "application/zip", "application/x-zip", "application/x-zip-compressed":
+		return true
+	}
+	return false
+}
+
+// listZipArchive lists a remote ZIP file from its central directory, fetched with range requests.
+func listZipArchive(ctx context.Context, client *http.Client, cfg Config, fullURL string) *ArchiveListing {
+	l := &ArchiveListing{Format: "zip"}
+	budget := cfg.ArchiveByteBudget
+	if budget <= 0 {
+		budget = 1 << 20
+	}
+
+	// The EOCD record ends the file, after a comment of up to 64 KiB and an optional ZIP64 locator.
+	tailLen := int64(zipEOCDLen + zipMaxCommentLen + zipEOCD64LocLen)
+	if tailLen > budget {
+		tailLen = budget
+	}
+	tail, tailStart, size, err := fetchRange(ctx, client, cfg, fullURL, "bytes=-"+strconv.FormatInt(tailLen, 10), tailLen)
+	l.BytesRead += int64(len(tail))
+	if err != nil {
+		return nil
+	}
+	l.Size = size
+
+	i := findZipEOCD(tail)
+	if i < 0 {
+		return nil
+	}
+	eocd := tail[i:]
+	total := uint64(binary.LittleEndian.Uint16(eocd[10:12]))
+	cdSize := uint64(binary.LittleEndian.Uint32(eocd[12:16]))
+	cdOff := uint64(binary.LittleEndian.Uint32(eocd[16:20]))
+
+	if total == 0xFFFF || cdSize == 0xFFFFFFFF || cdOff == 0xFFFFFFFF {
+		loc := i - zipEOCD64LocLen
+		if loc < 0 || binary.LittleEndian.Uint32(tail[loc:]) != zipEOCD64LocSig {
+			l.Error = "zip64 locator not found"
+			return l
+		}
+		recOff, ok := zipOffset(binary.LittleEndian.Uint64(tail[loc+8:]), zipEOCD64Len, size)
+		if !ok {
+			l.Error = "invalid zip64 end of central directory offset"
+			return l
+		}
+		var rec []byte
+		if recOff >= tailStart && recOff+zipEOCD64Len <= tailStart+int64(len(tail)) {
+			rec = tail[recOff-tailStart:]
+		} else {
+			rec, _, _, err = fetchRange(ctx, client, cfg, fullURL, fmt.Sprintf("bytes=%d-%d", recOff, recOff+zipEOCD64Len-1), zipEOCD64Len)
+			l.BytesRead += int64(len(rec))
+			if err != nil {
+				l.Error = err.Error()
+				return l
+			}
+		}
+		if len(rec) < zipEOCD64Len || binary.LittleEndian.Uint32(rec) != zipEOCD64Sig {
+			l.Error = "invalid zip64 end of central directory"
+			return l
+		}
+		total = binary.LittleEndian.Uint64(rec[32:40])
+		cdSize = binary.LittleEndian.Uint64(rec[40:48])
+		cdOff = binary.LittleEndian.Uint64(rec[48:56])
+	}
+	// Every entry takes at least a fixed-size header, which bounds the count by the directory size.
+	off, ok := zipOffset(cdOff, cdSize, size)
+	if !ok || total > cdSize/zipCentralHdrLen {
+		l.Error = "invalid central directory bounds"
+		return l
+	}
+	l.TotalEntries = int(total)
+	if cdSize == 0 {
+		return l
+	}
+
+	want := int64(cdSize)
+	if remaining := budget - l.BytesRead; want > remaining {
+		want = remaining
+		l.Truncated = true
+	}
+	if want <= 0 {
+		return l
+	}
+
+	var cd []byte
+	if off >= tailStart && off+want <= tailStart+int64(len(tail)) {
+		start := off - tailStart
+		cd = tail[start : start+want]
+	} else {
+		cd, _, _, err = fetchRange(ctx, client, cfg, fullURL, fmt.Sprintf("bytes=%d-%d", off, off+want-1), want)
+		l.BytesRead += int64(len(cd))
+		if err != nil {
+			l.Error = err.Error()
+			return l
+		}
+	}
+
+	parseZipCentralDirectory(cd, l)
+	if len(l.Entries) < l.TotalEntries {
+		l.Truncated = true
+	}
+	return l
+}
+
+// zipOffset checks that n bytes at off fit in an archive of the given size (-1 when unknown).
+func zipOffset(off, n uint64, size int64) (int64, bool) {
+	if off > math.MaxInt64 || n > math.MaxInt64-off {
+		return 0, false
+	}
+	if size >= 0 && off+n > uint64(size) {
+		return 0, false
+	}
+	return int64(off), true
+}
+
+// fetchRange requires a 206 so a server that ignores ranges cannot send the whole archive.
+func fetchRange(ctx context.Context, client *http.Client, cfg Config, fullURL, rng string, max int64) ([]byte, int64, int64, error) {
+	status, hdr, body, err := fetchRaw(ctx, client, cfg, fullURL, map[string]string{"Range": rng}, max)
+	if err != nil {
+		return body, 0, 0, err
+	}
+	if status != http.StatusPartialContent {
+		return nil, 0, 0, fmt.Errorf("range request not honoured (HTTP %d)", status)
+	}
+	start, size, ok := parseContentRange(hdr.Get("Content-Range"))
+	if !ok {
+		return nil, 0, 0, errors.New("missing or invalid Content-Range")
+	}
+	return body, start, size, nil
+}
+
+// parseContentRange parses "bytes start-end/size"; size is -1 when reported as "*".
+func parseContentRange(v string) (start, size int64, ok bool) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(strings.ToLower(v), "bytes ") {
+		return 0, 0, false
+	}
+	v = strings.TrimSpace(v[len("bytes "):])
+	rng, total, found := strings.Cut(v, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	size = -1
+	if total = strings.TrimSpace(total); total != "*" {
+		if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, size, true
+}
+
+func findZipEOCD(b []byte) int {
+	sig := []byte{0x50, 0x4b, 0x05, 0x06}
+	for i := len(b) - zipEOCDLen; i >= 0; i-- {
+		if !bytes.Equal(b[i:i+4], sig) {
+			continue
+		}
+		commentLen := int(binary.LittleEndian.Uint16(b[i+20 : i+22]))
+		if i+zipEOCDLen+commentLen <= len(b) {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseZipCentralDirectory(cd []byte, l *ArchiveListing) {
+	for off := 0; off+zipCentralHdrLen <= len(cd); {
+		h := cd[off:]
+		if binary.LittleEndian.Uint32(h) != zipCentralDirSig {
+			return
+		}
+		csize := uint64(binary.LittleEndian.Uint32(h[20:24]))
+		usize := uint64(binary.LittleEndian.Uint32(h[24:28]))
+		nameLen := int(binary.LittleEndian.Uint16(h[28:30]))
+		extraLen := int(binary.LittleEndian.Uint16(h[30:32]))
+		commentLen := int(binary.LittleEndian.Uint16(h[32:34]))
+		end := zipCentralHdrLen + nameLen + extraLen + commentLen
+		if end > len(h) {
+			// Entry cut off by the byte budget.
+			return
+		}
+		name := string(h[zipCentralHdrLen : zipCentralHdrLen+nameLen])
+		extra := h[zipCentralHdrLen+nameLen : zipCentralHdrLen+nameLen+extraLen]
+		usize, csize = zip64Sizes(extra, usize, csize)
+		off += end
+
+		sensitive := isSensitiveFileName(name)
+		if sensitive {
+			l.SensitiveEntries = append(l.SensitiveEntries, name)
+		}
+		if len(l.Entries) < maxArchiveEntries {
+			l.Entries = append(l.Entries, ArchiveEntry{Name: name, Size: usize, CompressedSize: csize, Sensitive: sensitive})
+		}
+	}
+}
+
+// zip64Sizes replaces saturated 32-bit sizes with the values from a ZIP64 extended information field.
+func zip64Sizes(extra []byte, usize, csize uint64) (uint64, uint64) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra[0:2])
+		n := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if 4+n > len(extra) {
+			break
+		}
+		field := extra[4 : 4+n]
+		extra = extra[4+n:]
+		if id != 0x0001 {
+			continue
+		}
+		if usize == 0xFFFFFFFF && len(field) >= 8 {
+			usize = binary.LittleEndian.Uint64(field)
+			field = field[8:]
+		}
+		if csize == 0xFFFFFFFF && len(field) >= 8 {
+			csize = binary.LittleEndian.Uint64(field)
+		}
+		break
+	}
+	return usize, csize
+}
+
+var sensitiveArchiveNames = map[string]struct{}{
+	"wp-config.php":    {},
+	".htpasswd":        {},
+	".git-credentials": {},
+	".npmrc":           {},
+	".pgpass":          {},
+	"id_rsa":           {},
+	"id_dsa":           {},
+	"id_ecdsa":         {},
+	"id_ed25519":       {},
+	"credentials":      {},
+	"settings.py":      {},
+	"web.config":       {},
+}
+
+var sensitiveArchiveExts = map[string]struct{}{
+	".pem":  {},
+	".key":  {},
+	".p12":  {},
+	".pfx":  {},
+	".sql":  {},
+	".kdbx": {},
+}
+
+func isSensitiveFileName(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasSuffix(name, "/") {
+		return false
+	}
+	base := strings.ToLower(path.Base(name))
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return true
+	}
+	if _, ok := sensitiveArchiveNames[base]; ok {
+		return true
+	}
+	if strings.Contains("/"+strings.ToLower(name), "/.git/config") {
+		return true
+	}
+	_, ok := sensitiveArchiveExts[path.Ext(base)]
+	return ok
+}
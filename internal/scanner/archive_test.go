@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// zipFile builds a regular archive with archive/zip, one small entry per name.
+func zipFile(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, n := range names {
+		w, err := zw.Create(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("data"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zip64File builds a ZIP64 central directory after some filler: one header per name with
+// saturated sizes and a ZIP64 extra field, the ZIP64 record and locator, and an EOCD whose
+// fields are all saturated. recOff overrides the locator's record offset when non-zero.
+func zip64File(recOff uint64, names ...string) []byte {
+	b := make([]byte, 64)
+	cdOff := uint64(len(b))
+	for _, n := range names {
+		h := make([]byte, zipCentralHdrLen)
+		binary.LittleEndian.PutUint32(h, zipCentralDirSig)
+		binary.LittleEndian.PutUint32(h[20:24], 0xFFFFFFFF)
+		binary.LittleEndian.PutUint32(h[24:28], 0xFFFFFFFF)
+		binary.LittleEndian.PutUint16(h[28:30], uint16(len(n)))
+		binary.LittleEndian.PutUint16(h[30:32], 20)
+		h = append(h, n...)
+		h = binary.LittleEndian.AppendUint16(h, 0x0001)
+		h = binary.LittleEndian.AppendUint16(h, 16)
+		h = binary.LittleEndian.AppendUint64(h, 5<<32)
+		h = binary.LittleEndian.AppendUint64(h, 1<<32)
+		b = append(b, h...)
+	}
+	cdSize := uint64(len(b)) - cdOff
+
+	if recOff == 0 {
+		recOff = uint64(len(b))
+	}
+	rec := make([]byte, zipEOCD64Len)
+	binary.LittleEndian.PutUint32(rec, zipEOCD64Sig)
+	binary.LittleEndian.PutUint64(rec[4:12], zipEOCD64Len-12)
+	binary.LittleEndian.PutUint64(rec[24:32], uint64(len(names)))
+	binary.LittleEndian.PutUint64(rec[32:40], uint64(len(names)))
+	binary.LittleEndian.PutUint64(rec[40:48], cdSize)
+	binary.LittleEndian.PutUint64(rec[48:56], cdOff)
+	b = append(b, rec...)
+
+	loc := make([]byte, zipEOCD64LocLen)
+	binary.LittleEndian.PutUint32(loc, zipEOCD64LocSig)
+	binary.LittleEndian.PutUint64(loc[8:16], recOff)
+	binary.LittleEndian.PutUint32(loc[16:20], 1)
+	b = append(b, loc...)
+
+	eocd := make([]byte, zipEOCDLen)
+	binary.LittleEndian.PutUint32(eocd, zipEOCDSig)
+	binary.LittleEndian.PutUint16(eocd[8:10], 0xFFFF)
+	binary.LittleEndian.PutUint16(eocd[10:12], 0xFFFF)
+	binary.LittleEndian.PutUint32(eocd[12:16], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(eocd[16:20], 0xFFFFFFFF)
+	return append(b, eocd...)
+}
+
+// withEOCD rewrites the 32-bit entry count, directory size and offset of a regular archive.
+func withEOCD(data []byte, total uint16, cdSize, cdOff uint32) []byte {
+	out := append([]byte(nil), data...)
+	eocd := out[len(out)-zipEOCDLen:]
+	binary.LittleEndian.PutUint16(eocd[10:12], total)
+	binary.LittleEndian.PutUint32(eocd[12:16], cdSize)
+	binary.LittleEndian.PutUint32(eocd[16:20], cdOff)
+	return out
+}
+
+func TestListZipArchive(t *testing.T) {
+	plain := zipFile(t, "index.html", "config/.env", "db/dump.sql")
+	eocd := plain[len(plain)-zipEOCDLen:]
+	cdSize := binary.LittleEndian.Uint32(eocd[12:16])
+	cdOff := binary.LittleEndian.Uint32(eocd[16:20])
+
+	tests := []struct {
+		name          string
+		data          []byte
+		ignoreRanges  bool
+		wantNil       bool
+		wantEntries   []string
+		wantSensitive []string
+		wantSize      uint64
+		wantErr       bool
+	}{
+		{
+			name:          "zip",
+			data:          plain,
+			wantEntries:   []string{"index.html", "config/.env", "db/dump.sql"},
+			wantSensitive: []string{"config/.env", "db/dump.sql"},
+			wantSize:      4,
+		},
+		{
+			name:          "zip64",
+			data:          zip64File(0, "site/", "site/id_rsa"),
+			wantEntries:   []string{"site/", "site/id_rsa"},
+			wantSensitive: []string{"site/id_rsa"},
+			wantSize:      5 << 32,
+		},
+		{name: "not a zip", data: []byte("<html>soft 404</html>"), wantNil: true},
+		{name: "ranges ignored", data: plain, ignoreRanges: true, wantNil: true},
+		{name: "zip64 record offset overflows int64", data: zip64File(math.MaxUint64-8, "a"), wantErr: true},
+		{name: "zip64 record past end", data: zip64File(1<<40, "a"), wantErr: true},
+		{name: "directory past end", data: withEOCD(plain, 3, cdSize, 1<<30), wantErr: true},
+		{name: "directory size past end", data: withEOCD(plain, 3, 1<<30, cdOff), wantErr: true},
+		{name: "entry count exceeds directory", data: withEOCD(plain, 0xFFFE, cdSize, cdOff), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.ignoreRanges {
+					w.Write(tt.data)
+					return
+				}
+				http.ServeContent(w, r, "site.zip", time.Time{}, bytes.NewReader(tt.data))
+			}))
+			defer srv.Close()
+
+			cfg := Config{Timeout: 5 * time.Second}
+			l := listZipArchive(context.Background(), srv.Client(), cfg, srv.URL+"/site.zip")
+			if tt.wantNil {
+				if l != nil {
+					t.Fatalf("listing = %+v, want nil", l)
+				}
+				return
+			}
+			if l == nil {
+				t.Fatal("listing = nil")
+			}
+			if (l.Error != "") != tt.wantErr {
+				t.Fatalf("error = %q, wantErr %v", l.Error, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, e := range l.Entries {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantEntries) || l.TotalEntries != len(tt.wantEntries) {
+				t.Errorf("entries = %q (total %d), want %q", names, l.TotalEntries, tt.wantEntries)
+			}
+			if !reflect.DeepEqual(l.SensitiveEntries, tt.wantSensitive) {
+				t.Errorf("sensitive = %q, want %q", l.SensitiveEntries, tt.wantSensitive)
+			}
+			if got := l.Entries[len(l.Entries)-1].Size; got != tt.wantSize {
+				t.Errorf("size = %d, want %d", got, tt.wantSize)
+			}
+			if l.Size != int64(len(tt.data)) {
+				t.Errorf("archive size = %d, want %d", l.Size, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestListZipArchiveBudget(t *testing.T) {
+	var names []string
+	for i := 0; i < 200; i++ {
+		names = append(names, "assets/file-with-a-long-name-"+string(rune('a'+i%26))+".txt")
+	}
+	data := zipFile(t, names...)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "site.zip", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	cfg := Config{Timeout: 5 * time.Second, ArchiveByteBudget: 4096}
+	l := listZipArchive(context.Background(), srv.Client(), cfg, srv.URL+"/site.zip")
+	if l == nil || l.Error != "" {
+		t.Fatalf("listing = %+v", l)
+	}
+	if !l.Truncated || l.BytesRead > cfg.ArchiveByteBudget || l.TotalEntries != len(names) {
+		t.Errorf("truncated = %v, bytes read = %d, total = %d", l.Truncated, l.BytesRead, l.TotalEntries)
+	}
+}
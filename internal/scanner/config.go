@@ -1,6 +1,9 @@
 package scanner
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
 
 type Config struct {
 	Concurrency int
@@ -14,5 +17,30 @@ type Config struct {
 	CrawlDepth    int
 	CrawlLimit    int
 
+	// CrawlWorkers bounds concurrent fetches; CrawlInclude/CrawlExclude filter crawled paths.
+	CrawlWorkers       int
+	CrawlRespectRobots bool
+	CrawlInclude       []*regexp.Regexp
+	CrawlExclude       []*regexp.Regexp
+
+	// EnableJSEndpoints mines pages and bundles for paths; JSMaxPaths caps how many it may add.
+	EnableJSEndpoints bool
+	JSMaxPaths        int
+
+	// EnableErrorProbes requests a non-existent path per target to surface debug pages.
+	EnableErrorProbes bool
+
+	ArchiveByteBudget int64
+
+	// FollowUpLimit and FollowUpDepth bound the paths inspections may add per target.
+	FollowUpLimit int
+	FollowUpDepth int
+
+	// FollowListings enqueues files and subdirectories parsed from directory listings.
+	FollowListings bool
+
+	// ProbeAPIs sends unauthenticated GETs to parameterless operations of exposed OpenAPI documents.
+	ProbeAPIs bool
+
 	IndexChecker IndexChecker `json:"-"`
 }
@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DebugLeak describes a framework debug page or verbose error found in a response.
+type DebugLeak struct {
+	Framework string   `json:"framework"`
+	DebugMode bool     `json:"debug_mode,omitempty"`
+	Versions  []string `json:"versions,omitempty"`
+	Paths     []string `json:"paths,omitempty"`
+}
+
+const maxDebugPageBytes = 512 << 10
+
+type debugSignature struct {
+	framework string
+	// debugMode marks interactive debug pages (as opposed to plain stack traces or warnings).
+	debugMode bool
+	// hints count on error responses only, whose snippet may stop before the signature.
+	hints   []string
+	match   *regexp.Regexp
+	version *regexp.Regexp
+	label   string
+}
+
+var debugSignatures = []debugSignature{
+	{
+		framework: "django",
+		debugMode: true,
+		hints:     []string{"DEBUG = True", "Django Version:", "Using the URLconf defined in", `id="traceback"`},
+		match:     regexp.MustCompile(`(?i)you have <code>DEBUG = True</code>|Django Version:|Using the URLconf defined in`),
+		version:   regexp.MustCompile(`Django Version:\s*</th>\s*<td>\s*([0-9][0-9.a-z]*)`),
+		label:     "Django",
+	},
+	{
+		framework: "laravel",
+		debugMode: true,
+		hints:     []string{"Ignition", "ignition", "laravel", "Laravel", "Whoops"},
+		match:     regexp.MustCompile(`(?i)window\.ignite|ignition-|Illuminate\\|Whoops, looks like something went wrong|class="Whoops|Whoops\\Exception|whoops-container`),
+		version:   regexp.MustCompile(`(?i)"?laravel_?version"?\s*[:=]\s*"?v?([0-9][0-9.]*)|Laravel\s+v?([0-9]+\.[0-9.]+)`),
+		label:     "Laravel",
+	},
+	{
+		framework: "werkzeug",
+		debugMode: true,
+		hints:     []string{"Werkzeug", "werkzeug", "__debugger__"},
+		match:     regexp.MustCompile(`(?i)Werkzeug Debugger|The debugger caught an exception in your WSGI application|__debugger__=yes`),
+		version:   regexp.MustCompile(`Werkzeug/([0-9][0-9.]*)`),
+		label:     "Werkzeug",
+	},
+	{
+		framework: "rails",
+		debugMode: true,
+		hints:     []string{"Action Controller", "Rails.root", "Routing Error", "ActionController"},
+		match:     regexp.MustCompile(`Action Controller: Exception caught|Rails\.root:|ActionController::RoutingError`),
+		version:   regexp.MustCompile(`Rails ([0-9]+\.[0-9.]+)`),
+		label:     "Rails",
+	},
+	{
+		framework: "aspnet",
+		debugMode: true,
+		hints:     []string{"Server Error in", "ASP.NET Version", "Stack Trace:"},
+		match:     regexp.MustCompile(`Server Error in '[^']*' Application|ASP\.NET Version:`),
+		version:   regexp.MustCompile(`ASP\.NET Version:\s*([0-9][0-9.]*)|\.NET Framework Version:\s*([0-9][0-9.]*)`),
+		label:     "ASP.NET",
+	},
+	{
+		framework: "java",
+		hints:     []string{".java:", "java.lang.", "Apache Tomcat"},
+		match:     regexp.MustCompile(`(?m)\bat [a-zA-Z_$][\w$]*(?:\.[\w$<>]+)+\([\w$]+\.java:\d+\)|\bjava\.lang\.[A-Za-z]+(?:Exception|Error)\b`),
+		version:   regexp.MustCompile(`Apache Tomcat/([0-9][0-9.]*)`),
+		label:     "Java",
+	},
+	{
+		framework: "php",
+		hints:     []string{" on line "},
+		match:     regexp.MustCompile(`(?i)(?:<b>)?(?:Warning|Fatal error|Notice|Parse error|Deprecated)(?:</b>)?:\s.*?\bin (?:<b>)?[A-Za-z]?:?[/\\][^<\s]+(?:</b>)? on line`),
+		label:     "PHP",
+	},
+}
+
+var debugPathRe = regexp.MustCompile(`(?:/(?:var|home|usr|srv|opt|app|www|Users|data|root)/[\w.\-/]+\.[A-Za-z]{1,6})|(?:\b[A-Z]:\\(?:[\w .\-]+\\)+[\w .\-]+\.[A-Za-z]{1,6})`)
+
+const maxDebugPaths = 25
+
+// mayBeDebugPage decides whether a full body is worth downloading.
+func mayBeDebugPage(snippet string, status int) bool {
+	if snippet == "" {
+		return false
+	}
+	for _, sig := range debugSignatures {
+		if sig.match.MatchString(snippet) {
+			return true
+		}
+		if status < 400 {
+			continue
+		}
+		for _, h := range sig.hints {
+			if strings.Contains(snippet, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detectDebugPage returns the first framework whose signature matches, with versions and leaked paths.
+func detectDebugPage(body string, headers map[string][]string) *DebugLeak {
+	for _, sig := range debugSignatures {
+		if !sig.match.MatchString(body) {
+			continue
+		}
+		d := &DebugLeak{Framework: sig.framework, DebugMode: sig.debugMode}
+
+		versions := make(map[string]struct{})
+		if sig.version != nil {
+			for _, m := range sig.version.FindAllStringSubmatch(body, 5) {
+				for _, v := range m[1:] {
+					if v != "" {
+						versions[sig.label+" "+v] = struct{}{}
+					}
+				}
+			}
+		}
+		for _, h := range []string{"Server", "X-Powered-By", "X-AspNet-Version"} {
+			if v := firstHeader(headers, h); v != "" {
+				versions[v] = struct{}{}
+			}
+		}
+		d.Versions = sortedKeys(versions)
+
+		seen := make(map[string]struct{})
+		for _, p := range debugPathRe.FindAllString(body, -1) {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			d.Paths = append(d.Paths, p)
+			if len(d.Paths) >= maxDebugPaths {
+				break
+			}
+		}
+		sort.Strings(d.Paths)
+		return d
+	}
+	return nil
+}
+
+func applyDebugEvidence(d *DebugLeak, a *Analysis) {
+	a.Interesting = true
+	if d.DebugMode {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "framework debug page exposed: "+d.Framework)
+	} else {
+		if severityRank(a.Severity) < severityRank(SeverityMedium) {
+			a.Severity = SeverityMedium
+		}
+		a.Reasons = append(a.Reasons, "verbose error output: "+d.Framework)
+	}
+	if len(d.Paths) > 0 {
+		a.Reasons = append(a.Reasons, "error page leaks server file paths")
+	}
+}
+
+// errorProbePaths returns a random path and one with a malformed extension.
+func errorProbePaths() []string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	tok := "wdf-" + hex.EncodeToString(b[:])
+	return []string{"/" + tok, "/" + tok + ".aspx~"}
+}
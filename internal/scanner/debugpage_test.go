@@ -0,0 +1,28 @@
+package scanner
+
+import "testing"
+
+func TestMayBeDebugPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		status  int
+		want    bool
+	}{
+		{"empty", "", 500, false},
+		{"blog post mentioning laravel", "<h1>Why we moved to Laravel</h1>", 200, false},
+		{"docs with java stack frame words", "See Foo.java: the constructor", 200, false},
+		{"tomcat footer", "<p>Apache Tomcat powered intranet</p>", 200, false},
+		{"prose with on line", "Orders placed on line 3 of the form", 200, false},
+		{"php warning on 200", "<b>Warning</b>: Undefined variable $x in <b>/var/www/index.php</b> on line <b>4</b>", 200, true},
+		{"java trace on 200", "at com.example.Foo.bar(Foo.java:42)", 200, true},
+		{"django debug page", "<th>Django Version:</th>", 200, true},
+		{"laravel hint on 500", "<title>Laravel</title>", 500, true},
+		{"tomcat hint on 404", "<h3>Apache Tomcat/9.0.1</h3>", 404, true},
+	}
+	for _, tt := range tests {
+		if got := mayBeDebugPage(tt.snippet, tt.status); got != tt.want {
+			t.Errorf("%s: mayBeDebugPage = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// DSStoreListing holds the file names recovered from an exposed .DS_Store file.
+type DSStoreListing struct {
+	Entries  []string `json:"entries,omitempty"`
+	Enqueued []string `json:"enqueued,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+const (
+	maxDSStoreBytes   = 1 << 20
+	maxDSStoreEntries = 2000
+	maxDSStoreNodes   = 4096
+)
+
+func isDSStorePath(p string) bool {
+	return path.Base(p) == ".DS_Store"
+}
+
+// inspectDSStore enqueues the names a .DS_Store lists, plus the .DS_Store of those that may be directories.
+func inspectDSStore(ctx context.Context, client *http.Client, cfg Config, j job, fullURL string) *DSStoreListing {
+	status, _, body, err := fetchRaw(ctx, client, cfg, fullURL, nil, maxDSStoreBytes)
+	if err != nil {
+		return &DSStoreListing{Error: err.Error()}
+	}
+	if status != http.StatusOK || !isDSStore(body) {
+		return nil
+	}
+
+	l := &DSStoreListing{}
+	names, err := parseDSStore(body)
+	if err != nil {
+		l.Error = err.Error()
+	}
+	l.Entries = names
+
+	dir := path.Dir(j.path)
+	for _, name := range names {
+		p := path.Join(dir, name)
+		sensitive := isSensitiveFileName(name)
+		if j.state.enqueue(pathPlan{Path: p, IsSensitive: sensitive, Critical: sensitive, Source: SourceDSStore, Referrer: fullURL, Depth: j.depth + 1}) {
+			l.Enqueued = append(l.Enqueued, p)
+		}
+		if path.Ext(name) == "" {
+			sub := path.Join(p, ".DS_Store")
+			if j.state.enqueue(pathPlan{Path: sub, Source: SourceDSStore, Referrer: fullURL, Depth: j.depth + 1}) {
+				l.Enqueued = append(l.Enqueued, sub)
+			}
+		}
+	}
+	return l
+}
+
+func isDSStore(b []byte) bool {
+	return len(b) >= 8 && binary.BigEndian.Uint32(b) == 1 && string(b[4:8]) == "Bud1"
+}
+
+// parseDSStore returns the unique names in a .DS_Store B-tree; offsets are relative to byte 4.
+func parseDSStore(b []byte) ([]string, error) {
+	if !isDSStore(b) {
+		return nil, errors.New("not a .DS_Store file")
+	}
+	d := b[4:]
+	if len(d) < 16 {
+		return nil, errors.New("truncated header")
+	}
+	infoOff := binary.BigEndian.Uint32(d[4:8])
+	info, ok := dsSlice(d, infoOff, 8)
+	if !ok {
+		return nil, errors.New("invalid allocator offset")
+	}
+
+	nblocks := int(binary.BigEndian.Uint32(info[0:4]))
+	pos := int(infoOff) + 8
+	slots := (nblocks + 255) / 256 * 256
+	// The count comes from the file; check the table fits before sizing anything by it.
+	if pos < 0 || pos > len(d) || slots > (len(d)-pos)/4 {
+		return nil, errors.New("truncated block table")
+	}
+	blocks := make([]uint32, 0, nblocks)
+	for i := 0; i < slots; i++ {
+		v, ok := dsUint32(d, pos)
+		if !ok {
+			return nil, errors.New("truncated block table")
+		}
+		if i < nblocks {
+			blocks = append(blocks, v)
+		}
+		pos += 4
+	}
+
+	ndirs, ok := dsUint32(d, pos)
+	if !ok {
+		return nil, errors.New("truncated directory")
+	}
+	pos += 4
+	root := -1
+	for i := 0; i < int(ndirs); i++ {
+		if pos >= len(d) {
+			return nil, errors.New("truncated directory")
+		}
+		n := int(d[pos])
+		pos++
+		if pos+n+4 > len(d) {
+			return nil, errors.New("truncated directory")
+		}
+		name := string(d[pos : pos+n])
+		pos += n
+		id := binary.BigEndian.Uint32(d[pos : pos+4])
+		pos += 4
+		if name == "DSDB" {
+			root = int(id)
+		}
+	}
+	if root < 0 {
+		return nil, errors.New("DSDB entry not found")
+	}
+
+	block := func(id int) ([]byte, bool) {
+		if id < 0 || id >= len(blocks) {
+			return nil, false
+		}
+		addr := blocks[id]
+		return dsSlice(d, addr&^0x1f, 1<<(addr&0x1f))
+	}
+
+	db, ok := block(root)
+	if !ok || len(db) < 4 {
+		return nil, errors.New("invalid DSDB block")
+	}
+
+	seen := make(map[string]struct{})
+	visited := 0
+	var walk func(id int) error
+	walk = func(id int) error {
+		visited++
+		if visited > maxDSStoreNodes {
+			return errors.New("too many nodes")
+		}
+		node, ok := block(id)
+		if !ok || len(node) < 8 {
+			return errors.New("invalid node block")
+		}
+		next := int(binary.BigEndian.Uint32(node[0:4]))
+		count := int(binary.BigEndian.Uint32(node[4:8]))
+		off := 8
+		for i := 0; i < count; i++ {
+			if next != 0 {
+				if off+4 > len(node) {
+					return errors.New("truncated node")
+				}
+				if err := walk(int(binary.BigEndian.Uint32(node[off : off+4]))); err != nil {
+					return err
+				}
+				off += 4
+			}
+			name, n, err := dsRecord(node[off:])
+			if err != nil {
+				return err
+			}
+			off += n
+			if name != "" && name != "." && len(seen) < maxDSStoreEntries {
+				seen[name] = struct{}{}
+			}
+		}
+		if next != 0 {
+			return walk(next)
+		}
+		return nil
+	}
+	err := walk(int(binary.BigEndian.Uint32(db[0:4])))
+
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, err
+}
+
+// dsRecord decodes one record and returns its file name and encoded length.
+func dsRecord(b []byte) (string, int, error) {
+	if len(b) < 4 {
+		return "", 0, errors.New("truncated record")
+	}
+	nlen := int(binary.BigEndian.Uint32(b[0:4]))
+	off := 4 + nlen*2
+	if nlen > 1024 || off+8 > len(b) {
+		return "", 0, errors.New("truncated record")
+	}
+	u := make([]uint16, nlen)
+	for i := range u {
+		u[i] = binary.BigEndian.Uint16(b[4+i*2:])
+	}
+	name := string(utf16.Decode(u))
+
+	typ := string(b[off+4 : off+8])
+	off += 8
+	var size int
+	switch typ {
+	case "bool":
+		size = 1
+	case "long", "shor", "type":
+		size = 4
+	case "comp", "dutc":
+		size = 8
+	case "blob", "ustr":
+		if off+4 > len(b) {
+			return "", 0, errors.New("truncated record")
+		}
+		size = int(binary.BigEndian.Uint32(b[off : off+4]))
+		if typ == "ustr" {
+			size *= 2
+		}
+		size += 4
+	default:
+		return "", 0, errors.New("unknown record type " + typ)
+	}
+	if off+size > len(b) {
+		return "", 0, errors.New("truncated record")
+	}
+	// Names are stored as displayed; never allow them to escape the listed directory.
+	if strings.ContainsAny(name, "/\x00") || name == ".." {
+		name = ""
+	}
+	return name, off + size, nil
+}
+
+func dsSlice(d []byte, off uint32, size int) ([]byte, bool) {
+	end := int(off) + size
+	if int(off) < 0 || end < int(off) || end > len(d) {
+		return nil, false
+	}
+	return d[off:end], true
+}
+
+func dsUint32(d []byte, pos int) (uint32, bool) {
+	if pos < 0 || pos+4 > len(d) {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(d[pos : pos+4]), true
+}
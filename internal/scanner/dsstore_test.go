@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// dsStoreFile builds a minimal .DS_Store: a DSDB block at 32, one leaf node at 128 holding
+// an Iloc blob record per name, and the allocator info block at 256. Offsets are relative to byte 4.
+func dsStoreFile(names ...string) []byte {
+	d := make([]byte, 256)
+	copy(d, "Bud1")
+	put := func(b []byte, v uint32) []byte { return binary.BigEndian.AppendUint32(b, v) }
+
+	binary.BigEndian.PutUint32(d[4:8], 256)
+	binary.BigEndian.PutUint32(d[12:16], 256)
+	binary.BigEndian.PutUint32(d[32:36], 2) // DSDB: root node is block 2
+
+	node := put(put(nil, 0), uint32(len(names)))
+	for _, n := range names {
+		u := utf16.Encode([]rune(n))
+		node = put(node, uint32(len(u)))
+		for _, c := range u {
+			node = binary.BigEndian.AppendUint16(node, c)
+		}
+		node = append(node, "Ilocblob"...)
+		node = put(node, 16)
+		node = append(node, make([]byte, 16)...)
+	}
+	copy(d[128:256], node)
+
+	info := put(put(nil, 3), 0)
+	slots := make([]uint32, 256)
+	slots[1] = 32 | 5
+	slots[2] = 128 | 7
+	for _, s := range slots {
+		info = put(info, s)
+	}
+	info = put(info, 1)
+	info = append(info, 4)
+	info = append(info, "DSDB"...)
+	info = put(info, 1)
+	d = append(d, info...)
+
+	return append([]byte{0, 0, 0, 1}, d...)
+}
+
+func TestParseDSStore(t *testing.T) {
+	names, err := parseDSStore(dsStoreFile("backup.zip", ".env"))
+	if err != nil {
+		t.Fatalf("valid file: %v", err)
+	}
+	if want := []string{".env", "backup.zip"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+}
+
+func TestParseDSStoreHostile(t *testing.T) {
+	hugeBlocks := make([]byte, 40)
+	copy(hugeBlocks, []byte{0, 0, 0, 1, 'B', 'u', 'd', '1'})
+	binary.BigEndian.PutUint32(hugeBlocks[8:12], 16)          // allocator info at d[16:]
+	binary.BigEndian.PutUint32(hugeBlocks[20:24], 0xFFFFFFFF) // nblocks
+
+	valid := dsStoreFile("a")
+	badDSDB := append([]byte(nil), valid...)
+	// Point the DSDB directory entry at a block id past the table.
+	binary.BigEndian.PutUint32(badDSDB[len(badDSDB)-4:], 1000)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not a ds_store", []byte("hello")},
+		{"truncated header", []byte{0, 0, 0, 1, 'B', 'u', 'd', '1', 0, 0}},
+		{"huge block count", hugeBlocks},
+		{"allocator offset past end", append(append([]byte(nil), valid[:8]...), 0, 0, 0, 0, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0)},
+		{"truncated block table", valid[:len(valid)-600]},
+		{"truncated directory", valid[:len(valid)-3]},
+		{"DSDB block out of range", badDSDB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseDSStore(tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// secretNameRe matches configuration or environment variable names that usually hold secrets.
+var secretNameRe = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|private|api[_.-]?key|access[_.-]?key|\.key$|_key$)`)
+
+// Evidence holds structured facts gathered by follow-up inspection of a response.
+type Evidence struct {
+	Archive  *ArchiveListing   `json:"archive,omitempty"`
+	Git      *GitExposure      `json:"git,omitempty"`
+	DSStore  *DSStoreListing   `json:"ds_store,omitempty"`
+	Listing  *DirectoryListing `json:"directory_listing,omitempty"`
+	API      *APIInventory     `json:"api,omitempty"`
+	Actuator *ActuatorExposure `json:"actuator,omitempty"`
+	PHPInfo  *PHPInfo          `json:"phpinfo,omitempty"`
+	Status   *ServerStatus     `json:"server_status,omitempty"`
+	Debug    *DebugLeak        `json:"debug,omitempty"`
+
+	Observability *ObservabilityExposure `json:"observability,omitempty"`
+	GraphQL       *GraphQLExposure       `json:"graphql,omitempty"`
+	JavaScript    *JSAsset               `json:"javascript,omitempty"`
+	Robots        *RobotsLeak            `json:"robots_leak,omitempty"`
+	Sitemap       *SitemapFlag           `json:"sitemap,omitempty"`
+	Index         *IndexMatch            `json:"search_index,omitempty"`
+}
+
+func (rr *RequestResult) evidence() *Evidence {
+	if rr.Evidence == nil {
+		rr.Evidence = &Evidence{}
+	}
+	return rr.Evidence
+}
+
+// inspect runs content-specific follow-up checks on a successful response.
+func inspect(ctx context.Context, client *http.Client, cfg Config, rs RuleSet, j job, rr *RequestResult, a *Analysis, flags *analysisFlags) {
+	// Path classifiers look at the path alone; rr.Path may carry a discovered query string.
+	p, _, _ := strings.Cut(rr.Path, "?")
+
+	inspectErrorPage(ctx, client, cfg, j, rr, a)
+	if isGraphQLCandidate(p, rr.StatusCode, rr.Snippet) {
+		if g := inspectGraphQL(ctx, client, cfg, j, rr); g != nil {
+			rr.evidence().GraphQL = g
+			applyGraphQLEvidence(g, a, flags)
+		}
+	}
+	if rr.StatusCode != http.StatusOK {
+		return
+	}
+
+	if isZipResponse(p, rr.Headers) {
+		if l := listZipArchive(ctx, client, cfg, rr.URL); l != nil {
+			rr.evidence().Archive = l
+			if len(l.SensitiveEntries) > 0 {
+				a.Severity = SeverityHigh
+				a.Interesting = true
+				a.Reasons = append(a.Reasons, "archive contains sensitive entries: "+joinLimited(l.SensitiveEntries, 5))
+			}
+		}
+	}
+
+	if dir, ok := gitDirOf(p); ok && j.state.claim("git:"+dir) {
+		if g := inspectGitRepository(ctx, client, cfg, rr.URL); g.Verified {
+			rr.evidence().Git = g
+			applyGitEvidence(g, a)
+		}
+	}
+
+	if isDSStorePath(p) {
+		if l := inspectDSStore(ctx, client, cfg, j, rr.URL); l != nil {
+			rr.evidence().DSStore = l
+			if len(l.Entries) > 0 {
+				a.Interesting = true
+				if severityRank(a.Severity) < severityRank(SeverityMedium) {
+					a.Severity = SeverityMedium
+				}
+				a.Reasons = append(a.Reasons, "DS_Store lists "+strconv.Itoa(len(l.Entries))+" file names")
+			}
+		}
+	}
+
+	if flags.DirectoryListing {
+		if l := inspectDirectoryListing(ctx, client, cfg, j, rr.URL); l != nil {
+			rr.evidence().Listing = l
+			if l.Total > 0 {
+				a.Reasons = append(a.Reasons, "directory listing exposes "+strconv.Itoa(l.Total)+" entries")
+			}
+		}
+	}
+
+	if isAPIDocCandidate(rr.Snippet) {
+		if inv := inspectAPIDocument(ctx, client, cfg, j, rr.URL, rr.Snippet); inv != nil {
+			rr.evidence().API = inv
+			applyAPIEvidence(inv, a)
+		}
+	}
+
+	if isActuatorIndex(rr.Snippet) {
+		if x := inspectActuatorIndex(ctx, client, cfg, j, rr.URL); x != nil {
+			rr.evidence().Actuator = x
+			applyActuatorEvidence(x, a, *flags)
+		}
+	} else if isActuatorEnvCandidate(p, rr.Headers, rr.Snippet) {
+		if x := inspectLegacyActuator(ctx, client, cfg, j, rr.URL); x != nil {
+			rr.evidence().Actuator = x
+			applyActuatorEvidence(x, a, *flags)
+		}
+	}
+
+	if isPHPInfo(rr.Snippet) && j.state.claim("phpinfo:"+rr.URL) {
+		if st, _, body, err := fetchRaw(ctx, client, cfg, rr.URL, nil, maxPHPInfoBytes); err == nil && st == http.StatusOK {
+			if info := parsePHPInfo(string(body)); info.valid() {
+				rr.evidence().PHPInfo = info
+				applyPHPInfoEvidence(info, a)
+			}
+		}
+	}
+
+	if isJavaScript(p, rr.Headers) && j.state.claim("js:"+rr.URL) {
+		if js := inspectJavaScript(ctx, client, cfg, rs, rr.URL); js != nil && (len(js.Secrets) > 0 || len(js.Keywords) > 0 || js.SourceMap != nil) {
+			rr.evidence().JavaScript = js
+			applyJSEvidence(js, a, flags)
+		}
+	}
+
+	if kind := observabilityKind(p, rr.Snippet); kind != "" {
+		if x := inspectObservability(ctx, client, cfg, rr.URL, kind); x != nil {
+			rr.evidence().Observability = x
+			applyObservabilityEvidence(x, a, flags)
+		}
+	}
+
+	if isServerStatus(p, rr.Snippet) {
+		if st, _, body, err := fetchRaw(ctx, client, cfg, rr.URL, nil, maxServerStatusBytes); err == nil && st == http.StatusOK {
+			status := parseServerStatus(string(body))
+			rr.evidence().Status = status
+			applyServerStatusEvidence(status, a)
+		}
+	}
+}
+
+// inspectErrorPage refetches error probes and the first server error of each status in full, since
+// non-200 HEAD responses carry no snippet. A framework is reported once per target.
+func inspectErrorPage(ctx context.Context, client *http.Client, cfg Config, j job, rr *RequestResult, a *Analysis) {
+	if rr.StatusCode == 0 {
+		return
+	}
+	needBody := j.source == SourceErrorProbe || mayBeDebugPage(rr.Snippet, rr.StatusCode) ||
+		(rr.StatusCode >= http.StatusInternalServerError && j.state.claim(fmt.Sprintf("debug:status:%d", rr.StatusCode)))
+	if !needBody {
+		return
+	}
+	st, hdr, body, err := fetchRaw(ctx, client, cfg, rr.URL, nil, maxDebugPageBytes)
+	if err != nil || st == 0 {
+		return
+	}
+	if d := detectDebugPage(string(body), hdr); d != nil && j.state.claim("debug:"+d.Framework) {
+		rr.evidence().Debug = d
+		applyDebugEvidence(d, a)
+	}
+}
+
+// joinLimited joins at most n values for use in a reason string, noting how many were omitted.
+func joinLimited(vals []string, n int) string {
+	if len(vals) <= n {
+		return strings.Join(vals, ", ")
+	}
+	return strings.Join(vals[:n], ", ") + fmt.Sprintf(" (+%d more)", len(vals)-n)
+}
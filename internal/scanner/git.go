@@ -0,0 +1,372 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GitExposure summarises what the metadata files of an exposed .git directory reveal.
+type GitExposure struct {
+	Verified       bool        `json:"verified"`
+	Head           string      `json:"head,omitempty"`
+	Branches       []string    `json:"branches,omitempty"`
+	Tags           []string    `json:"tags,omitempty"`
+	Remotes        []GitRemote `json:"remotes,omitempty"`
+	RecentAuthors  []string    `json:"recent_authors,omitempty"`
+	ReflogEntries  int         `json:"reflog_entries,omitempty"`
+	IndexVersion   int         `json:"index_version,omitempty"`
+	TrackedFiles   int         `json:"tracked_files,omitempty"`
+	Files          []string    `json:"files,omitempty"`
+	FilesTruncated bool        `json:"files_truncated,omitempty"`
+	Fetched        []string    `json:"fetched,omitempty"`
+	Errors         []string    `json:"errors,omitempty"`
+}
+
+type GitRemote struct {
+	Name                string `json:"name"`
+	URL                 string `json:"url"`
+	CredentialsEmbedded bool   `json:"credentials_embedded,omitempty"`
+}
+
+const (
+	maxGitMetaBytes   = 256 << 10
+	maxGitIndexBytes  = 4 << 20
+	maxGitListedFiles = 1000
+	maxGitAuthors     = 20
+)
+
+var (
+	gitSHARe        = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+	gitRemoteHdrRe  = regexp.MustCompile(`^\[remote\s+"([^"]+)"\]$`)
+	gitSectionHdrRe = regexp.MustCompile(`^\[[^\]]+\]$`)
+)
+
+// gitDirOf returns the repository directory when p is a metadata file that triggers inspection.
+func gitDirOf(p string) (string, bool) {
+	for _, suffix := range []string{"/.git/config", "/.git/HEAD"} {
+		if strings.HasSuffix(p, suffix) {
+			return strings.TrimSuffix(p, suffix[len("/.git/"):]), true
+		}
+	}
+	return "", false
+}
+
+// inspectGitRepository marks the repository verified only when HEAD or config look genuine.
+func inspectGitRepository(ctx context.Context, client *http.Client, cfg Config, triggerURL string) *GitExposure {
+	g := &GitExposure{}
+	base, err := url.Parse(triggerURL)
+	if err != nil {
+		g.Errors = append(g.Errors, err.Error())
+		return g
+	}
+
+	fetch := func(name string, max int64) []byte {
+		u := base.ResolveReference(&url.URL{Path: name}).String()
+		status, _, body, err := fetchRaw(ctx, client, cfg, u, nil, max)
+		if err != nil {
+			g.Errors = append(g.Errors, name+": "+err.Error())
+			return nil
+		}
+		if status != http.StatusOK {
+			return nil
+		}
+		g.Fetched = append(g.Fetched, name)
+		return body
+	}
+
+	branches := make(map[string]struct{})
+	tags := make(map[string]struct{})
+
+	if head := strings.TrimSpace(string(fetch("HEAD", 1024))); head != "" {
+		switch {
+		case strings.HasPrefix(head, "ref: refs/"):
+			g.Verified = true
+			ref := strings.TrimSpace(strings.TrimPrefix(head, "ref: "))
+			g.Head = ref
+			if b, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+				branches[b] = struct{}{}
+			}
+		case gitSHARe.MatchString(head):
+			g.Verified = true
+			g.Head = head
+		}
+	}
+
+	if conf := fetch("config", maxGitMetaBytes); looksLikeGitConfig(conf) {
+		g.Verified = true
+		g.Remotes = parseGitRemotes(conf)
+	}
+
+	if !g.Verified {
+		return g
+	}
+
+	if packed := fetch("packed-refs", maxGitMetaBytes); packed != nil {
+		for _, ref := range parsePackedRefs(packed) {
+			if b, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+				branches[b] = struct{}{}
+			} else if t, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+				tags[t] = struct{}{}
+			}
+		}
+	}
+
+	if logs := fetch("logs/HEAD", maxGitMetaBytes); logs != nil {
+		authors, entries, checkedOut := parseGitReflog(logs)
+		g.RecentAuthors = authors
+		g.ReflogEntries = entries
+		for _, b := range checkedOut {
+			branches[b] = struct{}{}
+		}
+	}
+
+	if idx := fetch("index", maxGitIndexBytes); idx != nil {
+		version, total, files, err := parseGitIndex(idx, maxGitListedFiles)
+		if err != nil {
+			g.Errors = append(g.Errors, "index: "+err.Error())
+		} else {
+			g.IndexVersion = version
+			g.TrackedFiles = total
+			g.Files = files
+			g.FilesTruncated = len(files) < total
+		}
+	}
+
+	g.Branches = sortedKeys(branches)
+	g.Tags = sortedKeys(tags)
+	return g
+}
+
+// applyGitEvidence adjusts the analysis for a verified repository exposure.
+func applyGitEvidence(g *GitExposure, a *Analysis) {
+	a.Interesting = true
+	a.Reasons = append(a.Reasons, "exposed git repository verified")
+	if severityRank(a.Severity) < severityRank(SeverityMedium) {
+		a.Severity = SeverityMedium
+	}
+	if g.TrackedFiles > 0 {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "git index lists "+strconv.Itoa(g.TrackedFiles)+" tracked files")
+	}
+	for _, r := range g.Remotes {
+		if r.CredentialsEmbedded {
+			a.Severity = SeverityHigh
+			a.Reasons = append(a.Reasons, "git remote URL embeds credentials")
+			break
+		}
+	}
+}
+
+func looksLikeGitConfig(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	s := strings.ToLower(string(b))
+	return strings.Contains(s, "[core]") || strings.Contains(s, "[remote ")
+}
+
+func parseGitRemotes(conf []byte) []GitRemote {
+	var out []GitRemote
+	current := ""
+	sc := bufio.NewScanner(bytes.NewReader(conf))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if m := gitRemoteHdrRe.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			continue
+		}
+		if gitSectionHdrRe.MatchString(line) {
+			current = ""
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(k), "url") {
+			continue
+		}
+		redacted, creds := redactRemoteURL(strings.TrimSpace(v))
+		out = append(out, GitRemote{Name: current, URL: redacted, CredentialsEmbedded: creds})
+	}
+	return out
+}
+
+// A password, or a user name long enough to be an access token, counts as a credential.
+func redactRemoteURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return raw, false
+	}
+	name := u.User.Username()
+	_, hasPassword := u.User.Password()
+	if !hasPassword && len(name) < 16 {
+		return raw, false
+	}
+	if hasPassword {
+		u.User = url.UserPassword(name, "REDACTED")
+	} else {
+		u.User = url.User("REDACTED")
+	}
+	return u.String(), true
+}
+
+func parsePackedRefs(b []byte) []string {
+	var out []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		sha, ref, ok := strings.Cut(line, " ")
+		if !ok || !gitSHARe.MatchString(sha) {
+			continue
+		}
+		out = append(out, strings.TrimSpace(ref))
+	}
+	return out
+}
+
+// parseGitReflog returns unique authors (most recent first), the entry count and checked-out branches.
+func parseGitReflog(b []byte) (authors []string, entries int, branches []string) {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64<<10), maxGitMetaBytes)
+	for sc.Scan() {
+		if line := sc.Text(); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for i := len(lines) - 1; i >= 0; i-- {
+		head, msg, _ := strings.Cut(lines[i], "\t")
+		fields := strings.SplitN(head, " ", 3)
+		if len(fields) < 3 || !gitSHARe.MatchString(fields[1]) {
+			continue
+		}
+		entries++
+		if end := strings.LastIndexByte(fields[2], '>'); end > 0 && len(authors) < maxGitAuthors {
+			who := strings.TrimSpace(fields[2][:end+1])
+			if _, ok := seen[who]; !ok {
+				seen[who] = struct{}{}
+				authors = append(authors, who)
+			}
+		}
+		if rest, ok := strings.CutPrefix(msg, "checkout: moving from "); ok {
+			if from, to, ok := strings.Cut(rest, " to "); ok {
+				for _, br := range []string{from, to} {
+					if br = strings.TrimSpace(br); br != "" && !gitSHARe.MatchString(br) {
+						branches = append(branches, br)
+					}
+				}
+			}
+		}
+	}
+	return authors, entries, branches
+}
+
+// parseGitIndex reads at most maxFiles path names from a DIRC index (versions 2-4) and the entry count.
+func parseGitIndex(b []byte, maxFiles int) (version int, total int, files []string, err error) {
+	if len(b) < 12 || string(b[:4]) != "DIRC" {
+		return 0, 0, nil, errors.New("not a git index")
+	}
+	version = int(binary.BigEndian.Uint32(b[4:8]))
+	if version < 2 || version > 4 {
+		return version, 0, nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	total = int(binary.BigEndian.Uint32(b[8:12]))
+
+	const fixed = 62
+	off := 12
+	var prev string
+	for i := 0; i < total; i++ {
+		if off+fixed > len(b) {
+			// Index larger than the byte budget; keep what we have.
+			return version, total, files, nil
+		}
+		flags := binary.BigEndian.Uint16(b[off+60 : off+62])
+		start := off + fixed
+		if version >= 3 && flags&0x4000 != 0 {
+			start += 2
+		}
+		if start > len(b) {
+			return version, total, files, nil
+		}
+
+		var name string
+		if version == 4 {
+			strip, n := readGitVarint(b[start:])
+			if n <= 0 || strip < 0 {
+				return version, total, files, nil
+			}
+			start += n
+			if start > len(b) {
+				return version, total, files, nil
+			}
+			end := bytes.IndexByte(b[start:], 0)
+			if end < 0 || strip > len(prev) {
+				return version, total, files, nil
+			}
+			name = prev[:len(prev)-strip] + string(b[start:start+end])
+			off = start + end + 1
+		} else {
+			end := bytes.IndexByte(b[start:], 0)
+			if end < 0 {
+				return version, total, files, nil
+			}
+			name = string(b[start : start+end])
+			// Entries are NUL-padded to a multiple of eight bytes.
+			entryLen := start + end - off
+			off += (entryLen + 8) &^ 7
+		}
+		prev = name
+		if len(files) < maxFiles {
+			files = append(files, name)
+		}
+	}
+	return version, total, files, nil
+}
+
+// readGitVarint decodes the index v4 offset varint; overflowing values come back negative.
+func readGitVarint(b []byte) (int, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	v := int(b[0] & 0x7f)
+	n := 1
+	for b[n-1]&0x80 != 0 {
+		if n >= len(b) || n > 9 {
+			return 0, 0
+		}
+		v = ((v + 1) << 7) | int(b[n]&0x7f)
+		n++
+	}
+	return v, n
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
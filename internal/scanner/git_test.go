@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// gitIndexEntry builds one index entry: 62 fixed bytes with flags at the end, the optional
+// extended flags word, then name. v2/v3 names are NUL-padded to a multiple of eight bytes; v4
+// names are prefixed by the strip varint and end in a single NUL.
+func gitIndexEntry(version int, extended bool, strip []byte, name string) []byte {
+	b := make([]byte, 62)
+	flags := uint16(len(name))
+	if extended {
+		flags |= 0x4000
+	}
+	binary.BigEndian.PutUint16(b[60:62], flags)
+	if extended {
+		b = append(b, 0, 0)
+	}
+	if version == 4 {
+		b = append(b, strip...)
+		return append(append(b, name...), 0)
+	}
+	b = append(b, name...)
+	n := len(b)
+	return append(b, make([]byte, (n+8)&^7-n)...)
+}
+
+func gitIndex(version, total int, entries ...[]byte) []byte {
+	b := []byte("DIRC")
+	b = binary.BigEndian.AppendUint32(b, uint32(version))
+	b = binary.BigEndian.AppendUint32(b, uint32(total))
+	for _, e := range entries {
+		b = append(b, e...)
+	}
+	return b
+}
+
+func TestParseGitIndex(t *testing.T) {
+	v2 := gitIndex(2, 2, gitIndexEntry(2, false, nil, "README.md"), gitIndexEntry(2, false, nil, "config/.env"))
+
+	tests := []struct {
+		name      string
+		data      []byte
+		maxFiles  int
+		wantFiles []string
+		wantErr   bool
+	}{
+		{name: "not an index", data: []byte("hello world!"), wantErr: true},
+		{name: "unsupported version", data: gitIndex(9, 0), wantErr: true},
+		{name: "v2", data: v2, maxFiles: 10, wantFiles: []string{"README.md", "config/.env"}},
+		{name: "v2 max files", data: v2, maxFiles: 1, wantFiles: []string{"README.md"}},
+		{name: "v2 truncated name", data: v2[:len(v2)-10], maxFiles: 10, wantFiles: []string{"README.md"}},
+		{name: "v2 truncated fixed part", data: v2[:40], maxFiles: 10},
+		{
+			name:     "v3 extended flag truncated after flag",
+			data:     gitIndex(3, 1, gitIndexEntry(3, true, nil, "secret.key")[:62]),
+			maxFiles: 10,
+		},
+		{
+			name:      "v3 extended",
+			data:      gitIndex(3, 1, gitIndexEntry(3, true, nil, "secret.key")),
+			maxFiles:  10,
+			wantFiles: []string{"secret.key"},
+		},
+		{
+			name:      "v4 prefix compression",
+			data:      gitIndex(4, 2, gitIndexEntry(4, false, []byte{0}, "dir/a.txt"), gitIndexEntry(4, false, []byte{5}, "b.txt")),
+			maxFiles:  10,
+			wantFiles: []string{"dir/a.txt", "dir/b.txt"},
+		},
+		{
+			name:      "v4 strip longer than previous name",
+			data:      gitIndex(4, 2, gitIndexEntry(4, false, []byte{0}, "a"), gitIndexEntry(4, false, []byte{9}, "b")),
+			maxFiles:  10,
+			wantFiles: []string{"a"},
+		},
+		{
+			name:     "v4 overflowing strip varint",
+			data:     gitIndex(4, 1, gitIndexEntry(4, false, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, "x")),
+			maxFiles: 10,
+		},
+		{name: "v4 truncated varint", data: gitIndex(4, 1, make([]byte, 62), []byte{0x80}), maxFiles: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, files, err := parseGitIndex(tt.data, tt.maxFiles)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestReadGitVarint(t *testing.T) {
+	tests := []struct {
+		in    []byte
+		v, n  int
+		check bool
+	}{
+		{in: []byte{0x05}, v: 5, n: 1, check: true},
+		{in: []byte{0x80, 0x00}, v: 128, n: 2, check: true},
+		{in: []byte{0x80}, v: 0, n: 0, check: true},
+		{in: nil, v: 0, n: 0, check: true},
+		// Overflowing values only need to be recognisable: either rejected or negative.
+		{in: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+	}
+	for _, tt := range tests {
+		v, n := readGitVarint(tt.in)
+		if tt.check && (v != tt.v || n != tt.n) {
+			t.Errorf("readGitVarint(%x) = %d, %d, want %d, %d", tt.in, v, n, tt.v, tt.n)
+		}
+		if !tt.check && n > 0 && v >= 0 && v < 1<<20 {
+			t.Errorf("readGitVarint(%x) = %d, %d, want overflow", tt.in, v, n)
+		}
+	}
+}
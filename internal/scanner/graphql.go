@@ -0,0 +1,262 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GraphQLExposure describes a GraphQL endpoint and what its introspection query revealed.
+type GraphQLExposure struct {
+	Endpoint      string   `json:"endpoint"`
+	UI            string   `json:"ui,omitempty"`
+	Introspection bool     `json:"introspection"`
+	QueryType     string   `json:"query_type,omitempty"`
+	MutationType  string   `json:"mutation_type,omitempty"`
+	Subscriptions bool     `json:"subscriptions,omitempty"`
+	Types         int      `json:"types,omitempty"`
+	Fields        int      `json:"fields,omitempty"`
+	Mutations     []string `json:"mutations,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+const (
+	maxGraphQLBytes     = 4 << 20
+	maxGraphQLMutations = 100
+)
+
+// graphQLIntrospection requests only kinds and names, enough to size the schema.
+const graphQLIntrospection = `query IntrospectionQuery{__schema{queryType{name}mutationType{name}subscriptionType{name}types{kind name fields(includeDeprecated:true){name}}}}`
+
+var (
+	graphQLPathNames = map[string]bool{"graphql": true, "graphiql": true, "playground": true, "graphql-playground": true, "gql": true, "altair": true}
+	graphQLUIMarkers = map[string]string{"graphiql": "graphiql", "graphql-playground": "playground", "graphqlplayground": "playground", "altair": "altair", "apollo-sandbox": "apollo sandbox"}
+	graphQLRefRe     = regexp.MustCompile(`(?i)(?:endpoint|url|fetch\()\s*[:(=]?\s*["']([^"'\s]*graphql[^"'\s]*)["']`)
+)
+
+// isGraphQLCandidate also accepts 400 and 405, which servers commonly return for a bare GET.
+func isGraphQLCandidate(p string, status int, snippet string) bool {
+	if status == http.StatusOK && graphQLUI(snippet) != "" {
+		return true
+	}
+	if !graphQLPathNames[strings.ToLower(path.Base(p))] {
+		return false
+	}
+	switch status {
+	case http.StatusOK, http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType:
+		return true
+	}
+	return false
+}
+
+func graphQLUI(snippet string) string {
+	ls := strings.ToLower(snippet)
+	if !strings.Contains(ls, "<html") && !strings.Contains(ls, "<!doctype") {
+		return ""
+	}
+	for marker, name := range graphQLUIMarkers {
+		if strings.Contains(ls, marker) {
+			return name
+		}
+	}
+	return ""
+}
+
+// inspectGraphQL confirms a server with {__typename}, then sends one read-only introspection query.
+func inspectGraphQL(ctx context.Context, client *http.Client, cfg Config, j job, rr *RequestResult) *GraphQLExposure {
+	pageURL, err := url.Parse(rr.URL)
+	if err != nil {
+		return nil
+	}
+	ui := ""
+	if rr.StatusCode == http.StatusOK {
+		ui = graphQLUI(rr.Snippet)
+	}
+
+	var candidates []string
+	if ui != "" {
+		if m := graphQLRefRe.FindStringSubmatch(rr.Snippet); m != nil {
+			candidates = append(candidates, resolveSameHost(pageURL, m[1]))
+		}
+	}
+	candidates = append(candidates, rr.URL)
+	if ui != "" {
+		candidates = append(candidates, resolveSameHost(pageURL, "/graphql"))
+	}
+
+	// IDE pages claim separately so an IDE is reported even when its endpoint was found directly.
+	for _, c := range candidates {
+		if c == "" || !j.state.claim("graphql:"+ui+":"+c) {
+			continue
+		}
+		if !isGraphQLEndpoint(ctx, client, cfg, c) {
+			continue
+		}
+		g := &GraphQLExposure{Endpoint: c, UI: ui}
+		introspectGraphQL(ctx, client, cfg, g)
+		return g
+	}
+	return nil
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors"`
+}
+
+type graphQLError struct {
+	Message    string          `json:"message"`
+	Locations  json.RawMessage `json:"locations"`
+	Path       json.RawMessage `json:"path"`
+	Extensions json.RawMessage `json:"extensions"`
+}
+
+func postGraphQL(ctx context.Context, client *http.Client, cfg Config, endpoint, query string, max int64) (*graphQLResponse, error) {
+	payload, _ := json.Marshal(map[string]string{"query": query})
+	_, _, body, err := postJSON(ctx, client, cfg, endpoint, payload, max)
+	if err != nil {
+		return nil, err
+	}
+	var resp graphQLResponse
+	if json.Unmarshal(body, &resp) != nil || (len(resp.Data) == 0 && len(resp.Errors) == 0) {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// isGraphQLEndpoint requires a string __typename or spec-shaped errors, not just a data/errors wrapper.
+func isGraphQLEndpoint(ctx context.Context, client *http.Client, cfg Config, endpoint string) bool {
+	resp, err := postGraphQL(ctx, client, cfg, endpoint, "query{__typename}", 64<<10)
+	if err != nil || resp == nil {
+		return false
+	}
+	var data struct {
+		Typename *string `json:"__typename"`
+	}
+	if json.Unmarshal(resp.Data, &data) == nil && data.Typename != nil {
+		return true
+	}
+	for _, e := range resp.Errors {
+		if e.Message != "" && (len(e.Locations) > 0 || len(e.Path) > 0 || len(e.Extensions) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func introspectGraphQL(ctx context.Context, client *http.Client, cfg Config, g *GraphQLExposure) {
+	resp, err := postGraphQL(ctx, client, cfg, g.Endpoint, graphQLIntrospection, maxGraphQLBytes)
+	if err != nil {
+		g.Error = err.Error()
+		return
+	}
+	if resp == nil {
+		return
+	}
+	var data struct {
+		Schema *struct {
+			QueryType        *struct{ Name string } `json:"queryType"`
+			MutationType     *struct{ Name string } `json:"mutationType"`
+			SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+			Types            []struct {
+				Kind   string `json:"kind"`
+				Name   string `json:"name"`
+				Fields []struct {
+					Name string `json:"name"`
+				} `json:"fields"`
+			} `json:"types"`
+		} `json:"__schema"`
+	}
+	if len(resp.Data) == 0 || json.Unmarshal(resp.Data, &data) != nil || data.Schema == nil {
+		if len(resp.Errors) > 0 {
+			g.Error = truncate(resp.Errors[0].Message, 200)
+		}
+		return
+	}
+
+	s := data.Schema
+	g.Introspection = true
+	if s.QueryType != nil {
+		g.QueryType = s.QueryType.Name
+	}
+	if s.MutationType != nil {
+		g.MutationType = s.MutationType.Name
+	}
+	g.Subscriptions = s.SubscriptionType != nil
+	for _, t := range s.Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		g.Types++
+		g.Fields += len(t.Fields)
+		if g.MutationType != "" && t.Name == g.MutationType {
+			for _, f := range t.Fields {
+				if len(g.Mutations) < maxGraphQLMutations {
+					g.Mutations = append(g.Mutations, f.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(g.Mutations)
+}
+
+// collectGraphQL lists each confirmed endpoint once, with its IDE and richest introspection.
+func collectGraphQL(tr *TargetResult) {
+	byEndpoint := make(map[string]*GraphQLExposure)
+	for _, r := range tr.Results {
+		if r.Evidence == nil || r.Evidence.GraphQL == nil {
+			continue
+		}
+		g := r.Evidence.GraphQL
+		prev, ok := byEndpoint[g.Endpoint]
+		if !ok {
+			byEndpoint[g.Endpoint] = g
+			continue
+		}
+		merged := *prev
+		if g.Introspection && !prev.Introspection {
+			merged = *g
+			merged.UI = prev.UI
+		}
+		if merged.UI == "" {
+			merged.UI = g.UI
+		}
+		byEndpoint[g.Endpoint] = &merged
+	}
+	tr.GraphQL = nil
+	for _, g := range byEndpoint {
+		tr.GraphQL = append(tr.GraphQL, *g)
+	}
+	sort.Slice(tr.GraphQL, func(a, b int) bool { return tr.GraphQL[a].Endpoint < tr.GraphQL[b].Endpoint })
+}
+
+func applyGraphQLEvidence(g *GraphQLExposure, a *Analysis, flags *analysisFlags) {
+	a.Interesting = true
+	a.Reasons = append(a.Reasons, "GraphQL endpoint detected")
+	if g.UI != "" {
+		a.Reasons = append(a.Reasons, "GraphQL IDE exposed: "+g.UI)
+	}
+	if !g.Introspection {
+		return
+	}
+	flags.GraphQLIntrospection = true
+	if severityRank(a.Severity) < severityRank(SeverityMedium) {
+		a.Severity = SeverityMedium
+	}
+	a.Reasons = append(a.Reasons, "GraphQL introspection is enabled")
+	if len(g.Mutations) > 0 {
+		a.Reasons = append(a.Reasons, "GraphQL schema exposes mutations: "+joinLimited(g.Mutations, 5))
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsGraphQLEndpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{name: "typename", body: `{"data": {"__typename": "Query"}}`, want: true},
+		{name: "validation error", body: `{"errors": [{"message": "Cannot query field", "locations": [{"line": 1, "column": 7}]}]}`, want: true},
+		{name: "error with extensions", body: `{"errors": [{"message": "GET query missing.", "extensions": {"code": "BAD_REQUEST"}}]}`, want: true},
+		{name: "null data", body: `{"data": null}`},
+		{name: "rest data envelope", body: `{"data": {"items": []}}`},
+		{name: "json api error", body: `{"errors": [{"status": "404", "title": "Not Found"}]}`},
+		{name: "bare error message", body: `{"errors": [{"message": "not found"}]}`},
+		{name: "html", body: `<html>not found</html>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got := isGraphQLEndpoint(context.Background(), srv.Client(), Config{Timeout: 5 * time.Second}, srv.URL+"/graphql")
+			if got != tt.want {
+				t.Errorf("isGraphQLEndpoint = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectGraphQL(t *testing.T) {
+	var introspections int
+	isIntrospection := func(r *http.Request) bool {
+		var req struct{ Query string }
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &req)
+		return strings.Contains(req.Query, "__schema")
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if isIntrospection(r) {
+			introspections++
+			w.Write([]byte(`{"data": {"__schema": {
+				"queryType": {"name": "Query"}, "mutationType": {"name": "Mutation"}, "subscriptionType": null,
+				"types": [
+					{"kind": "OBJECT", "name": "Query", "fields": [{"name": "me"}, {"name": "orders"}]},
+					{"kind": "OBJECT", "name": "Mutation", "fields": [{"name": "deleteUser"}, {"name": "createOrder"}]},
+					{"kind": "OBJECT", "name": "__Type", "fields": [{"name": "name"}]}]}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"__typename": "Query"}}`))
+	})
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if isIntrospection(r) {
+			introspections++
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": [{"status": "404", "title": "Not Found"}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := Config{Timeout: 5 * time.Second}
+	j := job{state: newTargetState()}
+	rr := &RequestResult{URL: srv.URL + "/graphql", StatusCode: http.StatusBadRequest}
+	g := inspectGraphQL(context.Background(), srv.Client(), cfg, j, rr)
+	want := &GraphQLExposure{
+		Endpoint:      srv.URL + "/graphql",
+		Introspection: true,
+		QueryType:     "Query",
+		MutationType:  "Mutation",
+		Types:         2,
+		Fields:        4,
+		Mutations:     []string{"createOrder", "deleteUser"},
+	}
+	if !reflect.DeepEqual(g, want) {
+		t.Fatalf("exposure = %+v\nwant %+v", g, want)
+	}
+
+	// A JSON:API 404 is not GraphQL and never receives the introspection query.
+	rr = &RequestResult{URL: srv.URL + "/api/graphql", StatusCode: http.StatusBadRequest}
+	if g := inspectGraphQL(context.Background(), srv.Client(), cfg, j, rr); g != nil {
+		t.Errorf("JSON:API endpoint = %+v, want nil", g)
+	}
+	if introspections != 1 {
+		t.Errorf("introspection queries = %d, want 1", introspections)
+	}
+}
+
+func TestCollectGraphQL(t *testing.T) {
+	endpoint := &GraphQLExposure{Endpoint: "https://a.example/graphql", Introspection: true, Types: 3}
+	ide := &GraphQLExposure{Endpoint: "https://a.example/graphql", UI: "graphiql"}
+	other := &GraphQLExposure{Endpoint: "https://a.example/admin/graphql"}
+	tr := &TargetResult{Results: []RequestResult{
+		{Path: "/graphiql", Evidence: &Evidence{GraphQL: ide}},
+		{Path: "/graphql", Evidence: &Evidence{GraphQL: endpoint}},
+		{Path: "/admin/graphql", Evidence: &Evidence{GraphQL: other}},
+		{Path: "/"},
+	}}
+	collectGraphQL(tr)
+	want := []GraphQLExposure{
+		*other,
+		{Endpoint: "https://a.example/graphql", UI: "graphiql", Introspection: true, Types: 3},
+	}
+	if !reflect.DeepEqual(tr.GraphQL, want) {
+		t.Errorf("graphql = %+v\nwant %+v", tr.GraphQL, want)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 	"unicode/utf8"
+
+	"github.com/Jason-0902/wdf/internal/discover"
 )
 
 func newHTTPClient(cfg Config) *http.Client {
@@ -40,15 +42,19 @@ func newHTTPClient(cfg Config) *http.Client {
 	}
 }
 
-func scanOne(parent context.Context, client *http.Client, cfg Config, rs RuleSet, base *url.URL, path string, isSensitive bool, critical bool, source DiscoverySource) RequestResult {
+func scanOne(parent context.Context, client *http.Client, cfg Config, rs RuleSet, j job) RequestResult {
 	start := time.Now()
+	base, path, isSensitive, critical, source := j.baseURL, j.path, j.isSensitive, j.critical, j.source
 	full := resolvePath(base, path)
 
 	rr := RequestResult{
-		URL:             full,
-		Path:            path,
-		Method:          "HEAD",
-		DiscoverySource: source,
+		URL:              full,
+		Path:             path,
+		Method:           "HEAD",
+		DiscoverySource:  source,
+		SitemapLastMod:   j.lastMod,
+		DiscoverySources: j.sources,
+		Referrer:         j.referrer,
 		Analysis: Analysis{
 			Severity:   SeverityLow,
 			Interesting: false,
@@ -69,27 +75,22 @@ func scanOne(parent context.Context, client *http.Client, cfg Config, rs RuleSet
 	}
 
 	a, flags := analyze(path, status, hdr, body, rs, isSensitive, critical)
+	inspect(parent, client, cfg, rs, j, &rr, &a, &flags)
 
-	// Optional indexability module (stubbed by default).
-	if cfg.IndexChecker != nil {
-		target := base.Scheme + "://" + base.Host
-		indexed, ierr := cfg.IndexChecker.IsIndexed(ctx, target, path)
-		if ierr == nil && indexed {
-			rr.IndexedExposed = true
-			if a.Severity != SeverityHigh {
-				a.Severity = SeverityHigh
-			}
-			a.Interesting = true
-			a.Reasons = append(a.Reasons, "indexed in search engine")
-		} else if ierr != nil {
-			// Keep errors non-fatal; record as a low-signal reason.
-			a.Reasons = append(a.Reasons, "index check error: "+ierr.Error())
-		}
+	var robots *discover.RobotsFile
+	if j.state != nil {
+		robots = j.state.robots
+	}
+	// Search index checks run per target once every path is scanned; see applyIndexChecks.
+	if ix := assessIndexability(&rr, robots); ix != nil {
+		applyIndexability(ix, &a, &flags)
+		rr.Indexability = ix
 	}
 
 	a.Reasons = dedupeStrings(a.Reasons)
 	rr.Analysis = a
 	rr.RecommendedFix = recommendedFix(path, source, a, flags, isSensitive)
+	rr.flags, rr.sensitive = flags, isSensitive
 	rr.DurationMs = time.Since(start).Milliseconds()
 	return rr
 }
@@ -186,5 +187,51 @@ func sanitizeSnippet(b []byte, contentType string) string {
 func resolvePath(base *url.URL, p string) string {
 	u := *base
 	ref := &url.URL{Path: p}
+	if pp, query, ok := strings.Cut(p, "?"); ok {
+		ref = &url.URL{Path: pp, RawQuery: query}
+	}
 	return u.ResolveReference(ref).String()
 }
+
+// fetchRaw issues a GET and returns at most max bytes of the raw body.
+func fetchRaw(parent context.Context, client *http.Client, cfg Config, fullURL string, extra map[string]string, max int64) (status int, headers http.Header, body []byte, err error) {
+	return sendRaw(parent, client, cfg, http.MethodGet, fullURL, nil, extra, max)
+}
+
+// postJSON sends payload as a JSON POST body and returns the raw response like fetchRaw.
+func postJSON(parent context.Context, client *http.Client, cfg Config, fullURL string, payload []byte, max int64) (status int, headers http.Header, body []byte, err error) {
+	return sendRaw(parent, client, cfg, http.MethodPost, fullURL, payload, map[string]string{"Content-Type": "application/json", "Accept": "application/json"}, max)
+}
+
+func sendRaw(parent context.Context, client *http.Client, cfg Config, method, fullURL string, payload []byte, extra map[string]string, max int64) (status int, headers http.Header, body []byte, err error) {
+	ctx, cancel := context.WithTimeout(parent, cfg.Timeout)
+	defer cancel()
+
+	var rb io.Reader
+	if payload != nil {
+		rb = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, rb)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	req.Header.Set("Accept", "*/*")
+	for k, v := range extra {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if max <= 0 {
+		max = 1 << 20
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, max))
+	return resp.StatusCode, resp.Header, body, err
+}
@@ -0,0 +1,199 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BingSearchEndpoint is the Bing Web Search API v7 endpoint.
+const BingSearchEndpoint = "https://api.bing.microsoft.com/v7.0/search"
+
+// BingIndexChecker looks URLs up against the Bing Web Search API, spacing requests MinInterval apart
+// to stay within the subscription's rate limit. MaxPages bounds the pages of 50 results per query.
+type BingIndexChecker struct {
+	SubscriptionKey string
+	Market          string
+	Endpoint        string
+	MinInterval     time.Duration
+	MaxPages        int
+	Client          *http.Client
+
+	cache lookupCache
+
+	rateMu sync.Mutex
+	next   time.Time
+}
+
+// NewBingIndexChecker returns a checker limited to three requests per second like the free tier.
+func NewBingIndexChecker(subscriptionKey, market string) *BingIndexChecker {
+	return &BingIndexChecker{
+		SubscriptionKey: subscriptionKey,
+		Market:          market,
+		Endpoint:        BingSearchEndpoint,
+		MinInterval:     time.Second / 3,
+		MaxPages:        2,
+		Client:          &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (b *BingIndexChecker) IsIndexed(ctx context.Context, target, path string) (bool, error) {
+	m, err := b.Lookup(ctx, target, path)
+	return m != nil, err
+}
+
+func (b *BingIndexChecker) Lookup(ctx context.Context, target, path string) (*IndexMatch, error) {
+	return b.cache.do(target+path, func() (*IndexMatch, error) { return b.search(ctx, target, path) })
+}
+
+type bingSearchResponse struct {
+	WebPages *struct {
+		TotalEstimatedMatches int `json:"totalEstimatedMatches"`
+		Value                 []struct {
+			URL     string `json:"url"`
+			Name    string `json:"name"`
+			Snippet string `json:"snippet"`
+		} `json:"value"`
+	} `json:"webPages"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+const bingPageSize = 50
+
+func (b *BingIndexChecker) search(ctx context.Context, target, path string) (*IndexMatch, error) {
+	if b.SubscriptionKey == "" {
+		return nil, errors.New("bing index checker: subscription key is required")
+	}
+	tu, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	q := "site:" + tu.Hostname()
+	if p, _, _ := strings.Cut(path, "?"); strings.Trim(p, "/") != "" {
+		q += " inurl:" + strings.Trim(p, "/")
+	}
+
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = BingSearchEndpoint
+	}
+	pages := b.MaxPages
+	if pages <= 0 {
+		pages = 1
+	}
+
+	for page := 0; page < pages; page++ {
+		v := url.Values{}
+		v.Set("q", q)
+		v.Set("count", strconv.Itoa(bingPageSize))
+		v.Set("offset", strconv.Itoa(page*bingPageSize))
+		v.Set("responseFilter", "Webpages")
+		if b.Market != "" {
+			v.Set("mkt", b.Market)
+		}
+		res, err := b.query(ctx, endpoint+"?"+v.Encode())
+		if err != nil {
+			return nil, err
+		}
+		if res.WebPages == nil {
+			break
+		}
+		for _, it := range res.WebPages.Value {
+			if indexedURLMatches(it.URL, target, path) {
+				return &IndexMatch{Engine: "bing", URL: it.URL, Title: it.Name, Snippet: it.Snippet}, nil
+			}
+		}
+		if len(res.WebPages.Value) < bingPageSize || (page+1)*bingPageSize >= res.WebPages.TotalEstimatedMatches {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// wait blocks until the next request may be sent under MinInterval.
+func (b *BingIndexChecker) wait(ctx context.Context) error {
+	b.rateMu.Lock()
+	now := time.Now()
+	at := b.next
+	if at.Before(now) {
+		at = now
+	}
+	b.next = at.Add(b.MinInterval)
+	b.rateMu.Unlock()
+
+	t := time.NewTimer(time.Until(at))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (b *BingIndexChecker) query(ctx context.Context, u string) (*bingSearchResponse, error) {
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// A 429 means the per-second limit was hit despite spacing; retry once after Retry-After.
+	for attempt := 0; ; attempt++ {
+		if err := b.wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Ocp-Apim-Subscription-Key", b.SubscriptionKey)
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("bing search: %w", err)
+		}
+
+		var res bingSearchResponse
+		derr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&res)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if derr != nil {
+				return nil, fmt.Errorf("bing search: %w", derr)
+			}
+			return &res, nil
+		}
+
+		msg := resp.Status
+		if res.Error != nil && res.Error.Message != "" {
+			msg = res.Error.Message
+		}
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && attempt == 0:
+			delay := time.Second
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 && s <= 10 {
+				delay = time.Duration(s) * time.Second
+			}
+			b.rateMu.Lock()
+			if at := time.Now().Add(delay); at.After(b.next) {
+				b.next = at
+			}
+			b.rateMu.Unlock()
+			continue
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return nil, fmt.Errorf("bing search: rate limited: %s", msg)
+		case resp.StatusCode == http.StatusForbidden && res.Error != nil && res.Error.Code == "OutOfCallVolume":
+			return nil, fmt.Errorf("bing search: %w: %s", ErrIndexQuota, msg)
+		}
+		return nil, fmt.Errorf("bing search: %s", msg)
+	}
+}
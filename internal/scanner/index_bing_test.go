@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestBingChecker(srv *httptest.Server) *BingIndexChecker {
+	b := NewBingIndexChecker("k", "en-US")
+	b.Endpoint, b.Client, b.MinInterval = srv.URL, srv.Client(), 0
+	return b
+}
+
+func TestBingIndexCheckerPagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		match     int
+		total     int
+		maxPages  int
+		wantFound bool
+		wantCalls int32
+	}{
+		{name: "first page", match: 10, total: 120, maxPages: 2, wantFound: true, wantCalls: 1},
+		{name: "second page", match: 70, total: 120, maxPages: 2, wantFound: true, wantCalls: 2},
+		{name: "beyond max pages", match: 110, total: 120, maxPages: 2, wantCalls: 2},
+		{name: "short last page", total: 60, maxPages: 5, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if r.Header.Get("Ocp-Apim-Subscription-Key") != "k" || r.URL.Query().Get("mkt") != "en-US" {
+					t.Errorf("missing key or market: %v", r.URL)
+				}
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				var res bingSearchResponse
+				res.WebPages = &struct {
+					TotalEstimatedMatches int `json:"totalEstimatedMatches"`
+					Value                 []struct {
+						URL     string `json:"url"`
+						Name    string `json:"name"`
+						Snippet string `json:"snippet"`
+					} `json:"value"`
+				}{TotalEstimatedMatches: tt.total}
+				for i := offset + 1; i <= offset+bingPageSize && i <= tt.total; i++ {
+					u := fmt.Sprintf("https://example.com/page%d", i)
+					if i == tt.match {
+						u = "http://example.com/admin/"
+					}
+					res.WebPages.Value = append(res.WebPages.Value, struct {
+						URL     string `json:"url"`
+						Name    string `json:"name"`
+						Snippet string `json:"snippet"`
+					}{URL: u, Name: "n" + strconv.Itoa(i)})
+				}
+				json.NewEncoder(w).Encode(res)
+			}))
+			defer srv.Close()
+
+			b := newTestBingChecker(srv)
+			b.MaxPages = tt.maxPages
+			m, err := b.Lookup(context.Background(), "https://example.com", "/admin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (m != nil) != tt.wantFound {
+				t.Fatalf("match = %+v, want found %v", m, tt.wantFound)
+			}
+			if m != nil && (m.Engine != "bing" || m.Title != "n"+strconv.Itoa(tt.match)) {
+				t.Errorf("match = %+v", m)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("%d API calls, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBingIndexCheckerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int // status per call; 0 means an empty 200 answer
+		body      string
+		wantErr   bool
+		quota     bool
+		wantCalls int32
+	}{
+		{name: "429 then success", responses: []int{429, 0}, wantCalls: 2},
+		{name: "429 twice", responses: []int{429, 429}, wantErr: true, wantCalls: 2},
+		{name: "out of call volume", responses: []int{403}, body: `{"error": {"code": "OutOfCallVolume", "message": "Out of call volume quota"}}`, wantErr: true, quota: true, wantCalls: 1},
+		{name: "invalid key", responses: []int{401}, body: `{"error": {"code": "InvalidSubscriptionKey", "message": "Access denied"}}`, wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1)) - 1
+				st := tt.responses[len(tt.responses)-1]
+				if n < len(tt.responses) {
+					st = tt.responses[n]
+				}
+				switch st {
+				case 0:
+					w.Write([]byte(`{"webPages": {"totalEstimatedMatches": 0, "value": []}}`))
+				case http.StatusTooManyRequests:
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(st)
+				default:
+					w.WriteHeader(st)
+					w.Write([]byte(tt.body))
+				}
+			}))
+			defer srv.Close()
+
+			b := newTestBingChecker(srv)
+			start := time.Now()
+			_, err := b.Lookup(context.Background(), "https://example.com", "/admin")
+			if (err != nil) != tt.wantErr || errors.Is(err, ErrIndexQuota) != tt.quota {
+				t.Fatalf("err = %v, want error %v, quota %v", err, tt.wantErr, tt.quota)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("%d API calls, want %d", n, tt.wantCalls)
+			}
+			if tt.responses[0] == http.StatusTooManyRequests && time.Since(start) < time.Second {
+				t.Errorf("retry did not honour Retry-After: %v", time.Since(start))
+			}
+		})
+	}
+}
+
+func TestBingIndexCheckerRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	b := newTestBingChecker(srv)
+	b.MinInterval = 50 * time.Millisecond
+	start := time.Now()
+	for _, p := range []string{"/a", "/b", "/c"} {
+		if _, err := b.Lookup(context.Background(), "https://example.com", p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 2 intervals", d)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("%d API calls, want 3", calls.Load())
+	}
+}
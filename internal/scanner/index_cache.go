@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CachedIndexChecker keeps the answers of Checker in a JSON file so repeated scans do not spend
+// search API quota. Entries older than TTL are asked again; errors are never cached.
+type CachedIndexChecker struct {
+	Checker   IndexChecker
+	Path      string
+	TTL       time.Duration
+	Namespace string
+
+	mu      sync.Mutex
+	entries map[string]indexCacheEntry
+	dirty   bool
+}
+
+type indexCacheEntry struct {
+	Indexed   bool        `json:"indexed"`
+	Match     *IndexMatch `json:"match,omitempty"`
+	CheckedAt time.Time   `json:"checked_at"`
+}
+
+// NewCachedIndexChecker loads the cache file at path, which need not exist yet.
+func NewCachedIndexChecker(checker IndexChecker, path string, ttl time.Duration, namespace string) (*CachedIndexChecker, error) {
+	c := &CachedIndexChecker{Checker: checker, Path: path, TTL: ttl, Namespace: namespace, entries: make(map[string]indexCacheEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &c.entries); err != nil {
+			return nil, errors.New("index cache " + path + ": " + err.Error())
+		}
+	}
+	return c, nil
+}
+
+func (c *CachedIndexChecker) IsIndexed(ctx context.Context, target, path string) (bool, error) {
+	m, err := c.Lookup(ctx, target, path)
+	return m != nil, err
+}
+
+func (c *CachedIndexChecker) Lookup(ctx context.Context, target, path string) (*IndexMatch, error) {
+	found, errs := c.LookupBatch(ctx, target, []string{path})
+	return found[path], errs[path]
+}
+
+// LookupBatch asks Checker only about paths without a fresh answer; new answers are kept until Save.
+func (c *CachedIndexChecker) LookupBatch(ctx context.Context, target string, paths []string) (map[string]*IndexMatch, map[string]error) {
+	found := make(map[string]*IndexMatch)
+	var misses []string
+	now := time.Now().UTC()
+
+	c.mu.Lock()
+	for _, p := range paths {
+		e, ok := c.entries[c.key(target, p)]
+		if !ok || (c.TTL > 0 && now.Sub(e.CheckedAt) > c.TTL) {
+			misses = append(misses, p)
+			continue
+		}
+		if e.Indexed {
+			m := e.Match
+			if m == nil {
+				m = &IndexMatch{URL: target + p}
+			}
+			found[p] = m
+		}
+	}
+	c.mu.Unlock()
+	if len(misses) == 0 {
+		return found, nil
+	}
+
+	f, errs := lookupIndexBatch(ctx, c.Checker, target, misses)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range misses {
+		if errs[p] != nil {
+			continue
+		}
+		m := f[p]
+		c.entries[c.key(target, p)] = indexCacheEntry{Indexed: m != nil, Match: m, CheckedAt: now}
+		c.dirty = true
+		if m != nil {
+			found[p] = m
+		}
+	}
+	return found, errs
+}
+
+func (c *CachedIndexChecker) key(target, path string) string {
+	return c.Namespace + " " + target + path
+}
+
+// Save atomically writes the cache file if it gained answers.
+func (c *CachedIndexChecker) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.Path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCachedIndexChecker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index-cache.json")
+	inner := newFakeIndexChecker([]string{"/a"}, []string{"/c"})
+	c, err := NewCachedIndexChecker(inner, path, time.Hour, "google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{"/a", "/b", "/c"}
+	found, errs := c.LookupBatch(context.Background(), "https://example.com", paths)
+	if found["/a"] == nil || found["/b"] != nil || errs["/c"] == nil {
+		t.Fatalf("found %v, errs %v", found, errs)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new run answers /a and /b from the file; the error for /c was not cached.
+	inner.asked = nil
+	c, err = NewCachedIndexChecker(inner, path, time.Hour, "google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, _ = c.LookupBatch(context.Background(), "https://example.com", paths)
+	if found["/a"] == nil || found["/b"] != nil {
+		t.Errorf("cached answers lost: %v", found)
+	}
+	if !reflect.DeepEqual(inner.asked, []string{"/c"}) {
+		t.Errorf("inner checker asked %q, want only /c", inner.asked)
+	}
+
+	// Entries older than the TTL are asked again.
+	c.entries[c.key("https://example.com", "/a")] = indexCacheEntry{Indexed: true, CheckedAt: time.Now().Add(-2 * time.Hour)}
+	inner.asked = nil
+	c.LookupBatch(context.Background(), "https://example.com", []string{"/a", "/b"})
+	if !reflect.DeepEqual(inner.asked, []string{"/a"}) {
+		t.Errorf("after expiry inner checker asked %q, want /a", inner.asked)
+	}
+
+	// A zero TTL keeps entries forever.
+	c.TTL = 0
+	c.entries[c.key("https://example.com", "/b")] = indexCacheEntry{CheckedAt: time.Now().Add(-24 * 365 * time.Hour)}
+	inner.asked = nil
+	c.LookupBatch(context.Background(), "https://example.com", []string{"/b"})
+	if len(inner.asked) != 0 {
+		t.Errorf("zero TTL: inner checker asked %q", inner.asked)
+	}
+
+	// Another namespace does not see these answers.
+	other, err := NewCachedIndexChecker(inner, path, time.Hour, "bing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner.asked = nil
+	other.LookupBatch(context.Background(), "https://example.com", []string{"/a"})
+	if !reflect.DeepEqual(inner.asked, []string{"/a"}) {
+		t.Errorf("other namespace: inner checker asked %q, want /a", inner.asked)
+	}
+}
@@ -1,11 +1,45 @@
 package scanner
 
-import "context"
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+)
 
 type IndexChecker interface {
 	IsIndexed(ctx context.Context, target, path string) (bool, error)
 }
 
+// IndexMatch is the search result that showed a URL as indexed.
+type IndexMatch struct {
+	Engine  string `json:"engine,omitempty"`
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
+	// Source and LastCrawled are set for URLs imported from search console exports.
+	Source      string `json:"source,omitempty"`
+	LastCrawled string `json:"last_crawled,omitempty"`
+}
+
+// IndexLookup is implemented by checkers that report the matching search result.
+type IndexLookup interface {
+	Lookup(ctx context.Context, target, path string) (*IndexMatch, error)
+}
+
+// BatchIndexLookup is implemented by checkers that answer many paths of one target at once. Paths
+// missing from the returned map are not indexed.
+type BatchIndexLookup interface {
+	LookupBatch(ctx context.Context, target string, paths []string) (found map[string]*IndexMatch, errs map[string]error)
+}
+
+// IndexCheckError records why a search index check failed for a result.
+type IndexCheckError struct {
+	Message       string `json:"message"`
+	QuotaExceeded bool   `json:"quota_exceeded,omitempty"`
+}
+
 // StubIndexChecker is a placeholder for future integrations (e.g., Custom Search APIs).
 // It never reports a URL as indexed.
 type StubIndexChecker struct{}
@@ -14,3 +48,172 @@ func (StubIndexChecker) IsIndexed(ctx context.Context, target, path string) (boo
 	return false, nil
 }
 
+// checkIndex asks ic about target+path, using Lookup when ic implements IndexLookup.
+func checkIndex(ctx context.Context, ic IndexChecker, target, path string) (bool, *IndexMatch, error) {
+	if l, ok := ic.(IndexLookup); ok {
+		m, err := l.Lookup(ctx, target, path)
+		return m != nil, m, err
+	}
+	indexed, err := ic.IsIndexed(ctx, target, path)
+	return indexed, nil, err
+}
+
+// lookupIndexBatch asks ic about every path, in one call when ic implements BatchIndexLookup.
+func lookupIndexBatch(ctx context.Context, ic IndexChecker, target string, paths []string) (map[string]*IndexMatch, map[string]error) {
+	if b, ok := ic.(BatchIndexLookup); ok {
+		return b.LookupBatch(ctx, target, paths)
+	}
+	found := make(map[string]*IndexMatch)
+	errs := make(map[string]error)
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			errs[p] = err
+			continue
+		}
+		indexed, m, err := checkIndex(ctx, ic, target, p)
+		switch {
+		case err != nil:
+			errs[p] = err
+		case indexed && m != nil:
+			found[p] = m
+		case indexed:
+			found[p] = &IndexMatch{URL: target + p}
+		}
+	}
+	return found, errs
+}
+
+// applyIndexChecks asks only about interesting 2xx results, so benign responses spend no quota.
+func applyIndexChecks(ctx context.Context, ic IndexChecker, tr *TargetResult) {
+	if ic == nil {
+		return
+	}
+	u, err := url.Parse(tr.Normalized)
+	if err != nil || u.Host == "" {
+		return
+	}
+	target := u.Scheme + "://" + u.Host
+
+	byPath := make(map[string][]*RequestResult)
+	var paths []string
+	for i := range tr.Results {
+		rr := &tr.Results[i]
+		if !rr.Analysis.Interesting || rr.StatusCode < 200 || rr.StatusCode >= 300 || rr.Path == "" {
+			continue
+		}
+		if _, ok := byPath[rr.Path]; !ok {
+			paths = append(paths, rr.Path)
+		}
+		byPath[rr.Path] = append(byPath[rr.Path], rr)
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	found, errs := lookupIndexBatch(ctx, ic, target, paths)
+	for _, p := range paths {
+		for _, rr := range byPath[p] {
+			if m := found[p]; m != nil {
+				applyIndexMatch(rr, m)
+			} else if err := errs[p]; err != nil {
+				rr.IndexCheckError = &IndexCheckError{Message: err.Error(), QuotaExceeded: errors.Is(err, ErrIndexQuota)}
+			}
+		}
+	}
+}
+
+func applyIndexMatch(rr *RequestResult, m *IndexMatch) {
+	rr.IndexedExposed = true
+	if m.URL != "" {
+		rr.evidence().Index = m
+	}
+	a := &rr.Analysis
+	if ix := rr.Indexability; ix != nil {
+		// The pre-check assessment no longer holds, including any downgrade it caused.
+		a.Reasons = removeStrings(a.Reasons, ix.analysisReasons)
+		ix.analysisReasons = nil
+		ix.Verdict = IndexIndexed
+		ix.Reasons = append([]string{"found in a search index"}, ix.Reasons...)
+	}
+	a.Reasons = append(a.Reasons, "indexed in search engine")
+	a.Severity = SeverityHigh
+	a.Interesting = true
+	rr.RecommendedFix = recommendedFix(rr.Path, rr.DiscoverySource, *a, rr.flags, rr.sensitive)
+}
+
+// removeStrings returns list without the values in drop, reusing its backing array.
+func removeStrings(list, drop []string) []string {
+	out := list[:0]
+	for _, s := range list {
+		if !containsString(drop, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ErrIndexQuota is returned once a search API reports its quota as exhausted.
+var ErrIndexQuota = errors.New("search API quota exceeded")
+
+// lookupCache memoises answers and short-circuits every lookup once the quota is exhausted.
+type lookupCache struct {
+	mu        sync.Mutex
+	answers   map[string]*IndexMatch
+	exhausted bool
+}
+
+func (c *lookupCache) do(key string, search func() (*IndexMatch, error)) (*IndexMatch, error) {
+	c.mu.Lock()
+	if m, ok := c.answers[key]; ok {
+		c.mu.Unlock()
+		return m, nil
+	}
+	if c.exhausted {
+		c.mu.Unlock()
+		return nil, ErrIndexQuota
+	}
+	c.mu.Unlock()
+
+	m, err := search()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if errors.Is(err, ErrIndexQuota) {
+			c.exhausted = true
+		}
+		return nil, err
+	}
+	if c.answers == nil {
+		c.answers = make(map[string]*IndexMatch)
+	}
+	c.answers[key] = m
+	return m, nil
+}
+
+// indexedURLMatches reports whether a URL returned by a search engine is target+path.
+func indexedURLMatches(link, target, path string) bool {
+	lk, ok := indexURLKey(link)
+	tk, tok := indexURLKey(target + path)
+	return ok && tok && lk == tk
+}
+
+// indexURLKey ignores scheme, host case, a leading "www.", a trailing slash, the fragment, parameter
+// order and tracking parameters, as search engines canonicalise freely.
+func indexURLKey(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(raw, "/") {
+		return "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	key := strings.TrimPrefix(strings.ToLower(u.Host), "www.") + strings.TrimSuffix(u.EscapedPath(), "/")
+	if q := meaningfulQuery(u.Query()); q != "" {
+		key += "?" + q
+	}
+	return key, true
+}
@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyIndexMatchUndoesIndexability(t *testing.T) {
+	ix := &Indexability{Verdict: IndexExcluded, Reasons: []string{"noindex for all crawlers"}, NoIndex: []string{"*"}}
+	a := Analysis{Severity: SeverityMedium, Reasons: []string{"sensitive path"}, Interesting: true}
+	flags := analysisFlags{}
+	applyIndexability(ix, &a, &flags)
+	if a.Severity != SeverityLow || len(a.Reasons) != 3 {
+		t.Fatalf("applyIndexability: severity %s, reasons %q", a.Severity, a.Reasons)
+	}
+
+	rr := &RequestResult{Path: "/admin/", Analysis: a, Indexability: ix, flags: flags, sensitive: true}
+	rr.RecommendedFix = recommendedFix(rr.Path, rr.DiscoverySource, a, flags, true)
+	if want := "Noindex is present; also restrict access if this content is sensitive."; rr.RecommendedFix != want {
+		t.Fatalf("fix before match = %q", rr.RecommendedFix)
+	}
+
+	applyIndexMatch(rr, &IndexMatch{Engine: "google", URL: "https://example.com/admin/"})
+	if rr.Analysis.Severity != SeverityHigh || !rr.IndexedExposed {
+		t.Errorf("severity = %s, indexed = %v", rr.Analysis.Severity, rr.IndexedExposed)
+	}
+	want := []string{"sensitive path", "indexed in search engine"}
+	if !reflect.DeepEqual(rr.Analysis.Reasons, want) {
+		t.Errorf("reasons = %q, want %q", rr.Analysis.Reasons, want)
+	}
+	if ix.Verdict != IndexIndexed {
+		t.Errorf("verdict = %s", ix.Verdict)
+	}
+	if want := recommendedFix(rr.Path, "", rr.Analysis, flags, true); rr.RecommendedFix != want || rr.RecommendedFix == "" {
+		t.Errorf("fix after match = %q, want %q", rr.RecommendedFix, want)
+	}
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+)
+
+// CompositeIndexChecker combines several checkers: a path is indexed when any of them finds it, or
+// with All set when every one does.
+type CompositeIndexChecker struct {
+	Checkers []IndexChecker
+	All      bool
+}
+
+func (c *CompositeIndexChecker) IsIndexed(ctx context.Context, target, path string) (bool, error) {
+	m, err := c.Lookup(ctx, target, path)
+	return m != nil, err
+}
+
+func (c *CompositeIndexChecker) Lookup(ctx context.Context, target, path string) (*IndexMatch, error) {
+	found, errs := c.LookupBatch(ctx, target, []string{path})
+	return found[path], errs[path]
+}
+
+func (c *CompositeIndexChecker) LookupBatch(ctx context.Context, target string, paths []string) (map[string]*IndexMatch, map[string]error) {
+	found := make(map[string]*IndexMatch)
+	failed := make(map[string][]error)
+	pending := append([]string(nil), paths...)
+
+	for _, ic := range c.Checkers {
+		if len(pending) == 0 {
+			break
+		}
+		f, errs := lookupIndexBatch(ctx, ic, target, pending)
+		next := pending[:0]
+		for _, p := range pending {
+			m, err := f[p], errs[p]
+			switch {
+			case err != nil:
+				failed[p] = append(failed[p], err)
+				if c.All {
+					// Without this checker's answer the path cannot be confirmed by all of them.
+					continue
+				}
+				next = append(next, p)
+			case m != nil && c.All:
+				if found[p] == nil {
+					found[p] = m
+				}
+				next = append(next, p)
+			case m != nil:
+				found[p] = m
+			case c.All:
+				delete(found, p)
+				delete(failed, p)
+			default:
+				next = append(next, p)
+			}
+		}
+		pending = next
+	}
+
+	out := make(map[string]error)
+	for _, p := range paths {
+		if c.All && len(failed[p]) > 0 {
+			delete(found, p)
+		}
+		if found[p] == nil && len(failed[p]) > 0 {
+			out[p] = errors.Join(failed[p]...)
+		}
+	}
+	return found, out
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fakeIndexChecker answers from fixed sets of indexed and failing paths and records what it was
+// asked.
+type fakeIndexChecker struct {
+	indexed map[string]bool
+	failing map[string]bool
+	asked   []string
+}
+
+func (f *fakeIndexChecker) IsIndexed(ctx context.Context, target, path string) (bool, error) {
+	f.asked = append(f.asked, path)
+	if f.failing[path] {
+		return false, errors.New("lookup failed")
+	}
+	return f.indexed[path], nil
+}
+
+func newFakeIndexChecker(indexed, failing []string) *fakeIndexChecker {
+	f := &fakeIndexChecker{indexed: make(map[string]bool), failing: make(map[string]bool)}
+	for _, p := range indexed {
+		f.indexed[p] = true
+	}
+	for _, p := range failing {
+		f.failing[p] = true
+	}
+	return f
+}
+
+func sortedMatchKeys(m map[string]*IndexMatch) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestCompositeIndexChecker(t *testing.T) {
+	paths := []string{"/a", "/b", "/c", "/d"}
+	tests := []struct {
+		name       string
+		all        bool
+		first      *fakeIndexChecker
+		second     *fakeIndexChecker
+		wantFound  []string
+		wantErrs   []string
+		wantSecond []string
+	}{
+		{
+			name:       "any asks later checkers only about paths not found",
+			first:      newFakeIndexChecker([]string{"/a"}, nil),
+			second:     newFakeIndexChecker([]string{"/b"}, nil),
+			wantFound:  []string{"/a", "/b"},
+			wantSecond: []string{"/b", "/c", "/d"},
+		},
+		{
+			name:       "any reports errors only for undecided paths",
+			first:      newFakeIndexChecker(nil, []string{"/a", "/b"}),
+			second:     newFakeIndexChecker([]string{"/a"}, nil),
+			wantFound:  []string{"/a"},
+			wantErrs:   []string{"/b"},
+			wantSecond: []string{"/a", "/b", "/c", "/d"},
+		},
+		{
+			name:       "all requires every checker",
+			all:        true,
+			first:      newFakeIndexChecker([]string{"/a", "/b"}, nil),
+			second:     newFakeIndexChecker([]string{"/b", "/c"}, nil),
+			wantFound:  []string{"/b"},
+			wantSecond: []string{"/a", "/b"},
+		},
+		{
+			name:       "all drops paths a checker failed on",
+			all:        true,
+			first:      newFakeIndexChecker([]string{"/a", "/b"}, []string{"/c"}),
+			second:     newFakeIndexChecker([]string{"/a"}, []string{"/b"}),
+			wantFound:  []string{"/a"},
+			wantErrs:   []string{"/b", "/c"},
+			wantSecond: []string{"/a", "/b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CompositeIndexChecker{Checkers: []IndexChecker{tt.first, tt.second}, All: tt.all}
+			found, errs := c.LookupBatch(context.Background(), "https://example.com", paths)
+			if got := sortedMatchKeys(found); !reflect.DeepEqual(got, tt.wantFound) {
+				t.Errorf("found = %q, want %q", got, tt.wantFound)
+			}
+			var gotErrs []string
+			for p := range errs {
+				gotErrs = append(gotErrs, p)
+			}
+			sort.Strings(gotErrs)
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("errors for %q, want %q", gotErrs, tt.wantErrs)
+			}
+			if !reflect.DeepEqual(tt.second.asked, tt.wantSecond) {
+				t.Errorf("second checker asked %q, want %q", tt.second.asked, tt.wantSecond)
+			}
+		})
+	}
+}
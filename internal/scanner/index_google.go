@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoogleCSEEndpoint is the Custom Search JSON API endpoint of Programmable Search Engine.
+const GoogleCSEEndpoint = "https://www.googleapis.com/customsearch/v1"
+
+// GoogleIndexChecker looks URLs up with "site:" and "inurl:" queries against a Programmable Search
+// Engine that searches the entire web. MaxPages bounds the pages of 10 results read per query.
+type GoogleIndexChecker struct {
+	APIKey   string
+	CX       string
+	BaseURL  string
+	MaxPages int
+	Client   *http.Client
+
+	cache lookupCache
+}
+
+// NewGoogleIndexChecker returns a checker for the public Custom Search JSON API.
+func NewGoogleIndexChecker(apiKey, cx string) *GoogleIndexChecker {
+	return &GoogleIndexChecker{
+		APIKey:   apiKey,
+		CX:       cx,
+		BaseURL:  GoogleCSEEndpoint,
+		MaxPages: 3,
+		Client:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (g *GoogleIndexChecker) IsIndexed(ctx context.Context, target, path string) (bool, error) {
+	m, err := g.Lookup(ctx, target, path)
+	return m != nil, err
+}
+
+func (g *GoogleIndexChecker) Lookup(ctx context.Context, target, path string) (*IndexMatch, error) {
+	return g.cache.do(target+path, func() (*IndexMatch, error) { return g.search(ctx, target, path) })
+}
+
+type googleCSEResponse struct {
+	Items []struct {
+		Link    string `json:"link"`
+		Title   string `json:"title"`
+		Snippet string `json:"snippet"`
+	} `json:"items"`
+	Queries struct {
+		NextPage []struct {
+			StartIndex int `json:"startIndex"`
+		} `json:"nextPage"`
+	} `json:"queries"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+func (g *GoogleIndexChecker) search(ctx context.Context, target, path string) (*IndexMatch, error) {
+	if g.APIKey == "" || g.CX == "" {
+		return nil, errors.New("google index checker: API key and cx are required")
+	}
+	tu, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	q := "site:" + tu.Hostname()
+	if p, _, _ := strings.Cut(path, "?"); strings.Trim(p, "/") != "" {
+		q += " inurl:" + strings.Trim(p, "/")
+	}
+
+	base := g.BaseURL
+	if base == "" {
+		base = GoogleCSEEndpoint
+	}
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	pages := g.MaxPages
+	if pages <= 0 {
+		pages = 1
+	}
+
+	start := 1
+	for page := 0; page < pages; page++ {
+		v := url.Values{}
+		v.Set("key", g.APIKey)
+		v.Set("cx", g.CX)
+		v.Set("q", q)
+		v.Set("num", "10")
+		v.Set("start", strconv.Itoa(start))
+		res, err := g.query(ctx, client, base+"?"+v.Encode())
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range res.Items {
+			if indexedURLMatches(it.Link, target, path) {
+				return &IndexMatch{Engine: "google", URL: it.Link, Title: it.Title, Snippet: it.Snippet}, nil
+			}
+		}
+		// The API serves at most 100 results per query.
+		if len(res.Queries.NextPage) == 0 || res.Queries.NextPage[0].StartIndex <= start || res.Queries.NextPage[0].StartIndex > 91 {
+			break
+		}
+		start = res.Queries.NextPage[0].StartIndex
+	}
+	return nil, nil
+}
+
+func (g *GoogleIndexChecker) query(ctx context.Context, client *http.Client, u string) (*googleCSEResponse, error) {
+	// Per-minute limits are retried once after Retry-After; only the daily quota is exhaustion.
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			// The request URL carries the API key; report the failure without it.
+			var ue *url.Error
+			if errors.As(err, &ue) {
+				err = ue.Err
+			}
+			return nil, fmt.Errorf("google search: %w", err)
+		}
+
+		var res googleCSEResponse
+		derr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&res)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if derr != nil {
+				return nil, fmt.Errorf("google search: %w", derr)
+			}
+			return &res, nil
+		}
+
+		msg := resp.Status
+		rateLimited := resp.StatusCode == http.StatusTooManyRequests
+		if res.Error != nil {
+			if res.Error.Message != "" {
+				msg = res.Error.Message
+			}
+			for _, e := range res.Error.Errors {
+				switch e.Reason {
+				case "dailyLimitExceeded", "quotaExceeded":
+					return nil, fmt.Errorf("google search: %w: %s", ErrIndexQuota, msg)
+				case "rateLimitExceeded", "userRateLimitExceeded":
+					rateLimited = true
+				}
+			}
+		}
+		switch {
+		case rateLimited && attempt == 0:
+			delay := time.Second
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 && s <= 60 {
+				delay = time.Duration(s) * time.Second
+			}
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			case <-t.C:
+			}
+			continue
+		case rateLimited:
+			return nil, fmt.Errorf("google search: rate limited: %s", msg)
+		}
+		return nil, fmt.Errorf("google search: %s", msg)
+	}
+}
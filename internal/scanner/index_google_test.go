@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// googleStandIn serves Custom Search responses with ten results per page, the indexed URL at
+// position match (1-based, 0 for none) among total results.
+func googleStandIn(t *testing.T, match, total int, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		q := r.URL.Query()
+		if q.Get("key") != "k" || q.Get("cx") != "cx" {
+			t.Errorf("missing credentials: %s", r.URL.RawQuery)
+		}
+		if q.Get("q") != "site:example.com inurl:backup/db.sql" {
+			t.Errorf("q = %q", q.Get("q"))
+		}
+		start, _ := strconv.Atoi(q.Get("start"))
+		var res googleCSEResponse
+		for i := start; i < start+10 && i <= total; i++ {
+			link := fmt.Sprintf("https://example.com/page%d", i)
+			if i == match {
+				link = "https://www.example.com/backup/db.sql"
+			}
+			res.Items = append(res.Items, struct {
+				Link    string `json:"link"`
+				Title   string `json:"title"`
+				Snippet string `json:"snippet"`
+			}{Link: link, Title: "t" + strconv.Itoa(i)})
+		}
+		if start+10 <= total {
+			res.Queries.NextPage = append(res.Queries.NextPage, struct {
+				StartIndex int `json:"startIndex"`
+			}{StartIndex: start + 10})
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func TestGoogleIndexCheckerPagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		match     int
+		total     int
+		maxPages  int
+		wantFound bool
+		wantCalls int32
+	}{
+		{name: "first page", match: 3, total: 30, maxPages: 3, wantFound: true, wantCalls: 1},
+		{name: "third page", match: 25, total: 30, maxPages: 3, wantFound: true, wantCalls: 3},
+		{name: "beyond max pages", match: 25, total: 30, maxPages: 2, wantCalls: 2},
+		{name: "not indexed", total: 15, maxPages: 3, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := googleStandIn(t, tt.match, tt.total, &calls)
+			defer srv.Close()
+
+			g := NewGoogleIndexChecker("k", "cx")
+			g.BaseURL, g.MaxPages, g.Client = srv.URL, tt.maxPages, srv.Client()
+			m, err := g.Lookup(context.Background(), "https://example.com", "/backup/db.sql")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (m != nil) != tt.wantFound {
+				t.Fatalf("match = %+v, want found %v", m, tt.wantFound)
+			}
+			if m != nil && (m.Engine != "google" || m.Title != "t"+strconv.Itoa(tt.match)) {
+				t.Errorf("match = %+v", m)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("%d API calls, want %d", n, tt.wantCalls)
+			}
+
+			// Answers, including "not indexed", are cached.
+			if _, err := g.Lookup(context.Background(), "https://example.com", "/backup/db.sql"); err != nil || calls.Load() != tt.wantCalls {
+				t.Errorf("second lookup: err %v, %d calls", err, calls.Load())
+			}
+		})
+	}
+}
+
+func TestGoogleIndexCheckerQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		quota     bool
+		wantCalls int32
+	}{
+		{"daily limit", http.StatusForbidden, `{"error": {"code": 403, "message": "Daily Limit Exceeded", "errors": [{"reason": "dailyLimitExceeded"}]}}`, true, 1},
+		{"quota exceeded", http.StatusTooManyRequests, `{"error": {"code": 429, "message": "Quota exceeded", "errors": [{"reason": "quotaExceeded"}]}}`, true, 1},
+		{"per-minute limit", http.StatusForbidden, `{"error": {"code": 403, "message": "User Rate Limit Exceeded", "errors": [{"reason": "userRateLimitExceeded"}]}}`, false, 4},
+		{"bad key", http.StatusBadRequest, `{"error": {"code": 400, "message": "API key not valid", "errors": [{"reason": "badRequest"}]}}`, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			g := NewGoogleIndexChecker("k", "cx")
+			g.BaseURL, g.Client = srv.URL, srv.Client()
+			_, err := g.Lookup(context.Background(), "https://example.com", "/a")
+			if err == nil || errors.Is(err, ErrIndexQuota) != tt.quota {
+				t.Fatalf("err = %v, want quota %v", err, tt.quota)
+			}
+
+			// Once the quota is gone no further queries are sent; a rate-limited query is retried once.
+			_, err = g.Lookup(context.Background(), "https://example.com", "/b")
+			if err == nil || calls.Load() != tt.wantCalls {
+				t.Errorf("second lookup: err %v, %d calls, want %d", err, calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGoogleIndexCheckerRateLimitRetry(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"rateLimitExceeded", `{"error": {"code": 429, "message": "Rate Limit Exceeded", "errors": [{"reason": "rateLimitExceeded"}]}}`},
+		{"bare 429", `{"error": {"code": 429, "message": "Too many requests"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(tt.body))
+					return
+				}
+				w.Write([]byte(`{"items": [{"link": "https://example.com/a", "title": "A"}]}`))
+			}))
+			defer srv.Close()
+
+			g := NewGoogleIndexChecker("k", "cx")
+			g.BaseURL, g.Client = srv.URL, srv.Client()
+			start := time.Now()
+			m, err := g.Lookup(context.Background(), "https://example.com", "/a")
+			if err != nil || m == nil {
+				t.Fatalf("match = %+v, err = %v", m, err)
+			}
+			if n := calls.Load(); n != 2 {
+				t.Errorf("%d API calls, want 2", n)
+			}
+			if time.Since(start) < time.Second {
+				t.Errorf("retry did not honour Retry-After: %v", time.Since(start))
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OfflineIndexChecker answers index checks from search console exports of indexed pages.
+type OfflineIndexChecker struct {
+	urls map[string]IndexMatch
+}
+
+// indexURLColumns are the header names, lower-cased, of URL columns in known exports.
+var indexURLColumns = []string{"url", "urls", "page", "pages", "top pages", "address", "link", "loc", "inspection url"}
+
+// indexDateColumns hold the last crawl date in known exports.
+var indexDateColumns = []string{"last crawled", "last crawl", "last crawl date", "crawl date", "last crawled date", "discovery date"}
+
+// LoadOfflineIndex reads CSV, TSV, JSON and plain URL list exports, chosen by extension or sniffed.
+func LoadOfflineIndex(paths ...string) (*OfflineIndexChecker, error) {
+	c := &OfflineIndexChecker{urls: make(map[string]IndexMatch)}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\ufeff"))
+		name := filepath.Base(p)
+
+		var entries []IndexMatch
+		switch format := indexExportFormat(p, data); format {
+		case "json":
+			entries, err = parseIndexJSON(data)
+		case "csv":
+			entries, err = parseIndexCSV(data)
+		default:
+			entries = parseIndexList(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("index import %s: %w", p, err)
+		}
+		for _, e := range entries {
+			k, ok := indexURLKey(e.URL)
+			if !ok {
+				continue
+			}
+			if _, dup := c.urls[k]; dup {
+				continue
+			}
+			e.Engine, e.Source = "import", name
+			c.urls[k] = e
+		}
+	}
+	return c, nil
+}
+
+// Len returns the number of distinct URLs loaded.
+func (c *OfflineIndexChecker) Len() int {
+	return len(c.urls)
+}
+
+func (c *OfflineIndexChecker) IsIndexed(ctx context.Context, target, path string) (bool, error) {
+	m, err := c.Lookup(ctx, target, path)
+	return m != nil, err
+}
+
+func (c *OfflineIndexChecker) Lookup(ctx context.Context, target, path string) (*IndexMatch, error) {
+	k, ok := indexURLKey(target + path)
+	if !ok {
+		return nil, nil
+	}
+	if m, ok := c.urls[k]; ok {
+		return &m, nil
+	}
+	return nil, nil
+}
+
+func (c *OfflineIndexChecker) LookupBatch(ctx context.Context, target string, paths []string) (map[string]*IndexMatch, map[string]error) {
+	found := make(map[string]*IndexMatch)
+	for _, p := range paths {
+		if m, _ := c.Lookup(ctx, target, p); m != nil {
+			found[p] = m
+		}
+	}
+	return found, nil
+}
+
+func indexExportFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".csv", ".tsv":
+		return "csv"
+	case ".txt", ".lst":
+		return "list"
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return "json"
+	}
+	first, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if bytes.ContainsAny(first, ",\t;") {
+		return "csv"
+	}
+	return "list"
+}
+
+func parseIndexList(data []byte) []IndexMatch {
+	var out []IndexMatch
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, IndexMatch{URL: line})
+	}
+	return out
+}
+
+// parseIndexCSV finds the URL column by its header, or else by the first cell holding a URL.
+func parseIndexCSV(data []byte) ([]IndexMatch, error) {
+	first, _, _ := bytes.Cut(data, []byte("\n"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	switch {
+	case bytes.Count(first, []byte("\t")) > bytes.Count(first, []byte(",")):
+		r.Comma = '\t'
+	case bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")):
+		r.Comma = ';'
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	urlCol, dateCol, start := -1, -1, 0
+	if len(rows) > 0 {
+		for i, h := range rows[0] {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if urlCol < 0 && containsString(indexURLColumns, h) {
+				urlCol = i
+			}
+			if dateCol < 0 && containsString(indexDateColumns, h) {
+				dateCol = i
+			}
+		}
+		if urlCol >= 0 {
+			start = 1
+		}
+	}
+	if urlCol < 0 {
+		for _, row := range rows {
+			for i, v := range row {
+				if v = strings.TrimSpace(v); strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+					urlCol = i
+					break
+				}
+			}
+			if urlCol >= 0 {
+				break
+			}
+		}
+	}
+	if urlCol < 0 {
+		return nil, nil
+	}
+
+	var out []IndexMatch
+	for _, row := range rows[start:] {
+		if urlCol >= len(row) {
+			continue
+		}
+		m := IndexMatch{URL: strings.TrimSpace(row[urlCol])}
+		if dateCol >= 0 && dateCol < len(row) {
+			m.LastCrawled = strings.TrimSpace(row[dateCol])
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func parseIndexJSON(data []byte) ([]IndexMatch, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	var out []IndexMatch
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case string:
+			if looksLikeIndexURL(t) {
+				out = append(out, IndexMatch{URL: t})
+			}
+		case []any:
+			for _, e := range t {
+				walk(e)
+			}
+		case map[string]any:
+			if m, ok := indexJSONRecord(t); ok {
+				out = append(out, m)
+				return
+			}
+			if _, row := t["keys"]; row {
+				// A Search Analytics row without a page dimension holds queries or countries.
+				return
+			}
+			// Wrappers such as {"urls": [...]}, {"pages": [...]} or {"rows": [...]}.
+			for _, e := range t {
+				if _, ok := e.([]any); ok {
+					walk(e)
+				}
+			}
+		}
+	}
+	walk(v)
+	return out, nil
+}
+
+// indexJSONRecord reads an object with a URL field, or a Search Analytics row keyed by page.
+func indexJSONRecord(obj map[string]any) (IndexMatch, bool) {
+	var m IndexMatch
+	for k, v := range obj {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		lk := strings.ToLower(k)
+		switch {
+		case m.URL == "" && containsString(indexURLColumns, lk):
+			m.URL = s
+		case m.LastCrawled == "" && (containsString(indexDateColumns, lk) || lk == "lastcrawled" || lk == "lastcrawltime"):
+			m.LastCrawled = s
+		}
+	}
+	if keys, ok := obj["keys"].([]any); ok && m.URL == "" {
+		for _, k := range keys {
+			if s, ok := k.(string); ok && strings.Contains(s, "://") {
+				m.URL = s
+				break
+			}
+		}
+	}
+	return m, m.URL != ""
+}
+
+// looksLikeIndexURL accepts scheme-less URLs such as example.com/a, but not queries or countries.
+func looksLikeIndexURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && !strings.ContainsAny(s, " \t\n") && (strings.Contains(s, "://") || strings.Contains(s, "."))
+}
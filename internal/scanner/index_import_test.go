@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIndexExportFormat(t *testing.T) {
+	tests := []struct {
+		path, data, want string
+	}{
+		{"pages.json", "https://example.com/a", "json"},
+		{"pages.tsv", "https://example.com/a", "csv"},
+		{"pages.txt", "URL,Last crawled", "list"},
+		{"export", `  [{"url": "https://example.com/a"}]`, "json"},
+		{"export", `{"rows": []}`, "json"},
+		{"export", "URL,Last crawled\nhttps://example.com/a,2024-01-01", "csv"},
+		{"export", "Top pages\tClicks\nhttps://example.com/a\t3", "csv"},
+		{"export", "Page;Impressions\nhttps://example.com/a;3", "csv"},
+		{"export", "https://example.com/a\nhttps://example.com/b", "list"},
+	}
+	for _, tt := range tests {
+		if got := indexExportFormat(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("indexExportFormat(%q, %q) = %q, want %q", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestParseIndexCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []IndexMatch
+	}{
+		{
+			name: "search console pages with crawl date",
+			data: "URL,Last crawled\nhttps://example.com/a,2024-01-02\nhttps://example.com/b,2024-01-03\n",
+			want: []IndexMatch{{URL: "https://example.com/a", LastCrawled: "2024-01-02"}, {URL: "https://example.com/b", LastCrawled: "2024-01-03"}},
+		},
+		{
+			name: "tab separated with other columns first",
+			data: "Clicks\tTop pages\n3\thttps://example.com/a\n",
+			want: []IndexMatch{{URL: "https://example.com/a"}},
+		},
+		{
+			name: "semicolon separated",
+			data: "Page;Impressions\nhttps://example.com/a;10\n",
+			want: []IndexMatch{{URL: "https://example.com/a"}},
+		},
+		{
+			name: "no header, URL column found by content",
+			data: "12,https://example.com/a\n4,https://example.com/b\n",
+			want: []IndexMatch{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+		},
+		{name: "no URLs", data: "a,b\n1,2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIndexCSV([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIndexJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []IndexMatch
+	}{
+		{
+			name: "array of URLs",
+			data: `["https://example.com/a", "https://example.com/b"]`,
+			want: []IndexMatch{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+		},
+		{
+			name: "array of objects",
+			data: `[{"URL": "https://example.com/a", "lastCrawled": "2024-01-02", "clicks": 3}]`,
+			want: []IndexMatch{{URL: "https://example.com/a", LastCrawled: "2024-01-02"}},
+		},
+		{
+			name: "wrapped array",
+			data: `{"pages": [{"page": "https://example.com/a"}], "total": 1}`,
+			want: []IndexMatch{{URL: "https://example.com/a"}},
+		},
+		{
+			name: "search analytics rows",
+			data: `{"rows": [{"keys": ["https://example.com/a", "query"], "clicks": 1}, {"keys": ["example.com", "https://example.com/b"]}, {"keys": ["example.com", "de"]}]}`,
+			want: []IndexMatch{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+		},
+		{
+			name: "values that are not URLs",
+			data: `{"urls": ["https://example.com/a", "not a url", "admin", ""]}`,
+			want: []IndexMatch{{URL: "https://example.com/a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIndexJSON([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if _, err := parseIndexJSON([]byte(`[{"url": `)); err == nil {
+		t.Error("truncated JSON: expected an error")
+	}
+}
+
+func TestParseIndexList(t *testing.T) {
+	got := parseIndexList([]byte("# exported pages\nhttps://example.com/a\n\n  example.com/b  \n"))
+	want := []IndexMatch{{URL: "https://example.com/a"}, {URL: "example.com/b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadOfflineIndex(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	csv := write("coverage.csv", "\ufeffURL,Last crawled\nhttps://www.example.com/admin/,2024-01-02\n")
+	list := write("bing-pages", "https://example.com/admin\nhttp://example.com/backup.zip?utm_source=x\n")
+
+	ic, err := LoadOfflineIndex(csv, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ic.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", ic.Len())
+	}
+	m, _ := ic.Lookup(context.Background(), "https://example.com", "/admin")
+	if m == nil || m.Source != "coverage.csv" || m.LastCrawled != "2024-01-02" || m.Engine != "import" {
+		t.Errorf("/admin = %+v, want the first export's entry", m)
+	}
+	if m, _ := ic.Lookup(context.Background(), "https://example.com", "/backup.zip"); m == nil {
+		t.Error("/backup.zip not found")
+	}
+	if m, _ := ic.Lookup(context.Background(), "https://example.com", "/other"); m != nil {
+		t.Errorf("/other = %+v, want not indexed", m)
+	}
+
+	if _, err := LoadOfflineIndex(write("broken.json", `[{"url": `)); err == nil {
+		t.Error("broken export: expected an error")
+	}
+	if _, err := LoadOfflineIndex(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("missing file: expected an error")
+	}
+}
@@ -0,0 +1,244 @@
+package scanner
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Jason-0902/wdf/internal/discover"
+)
+
+// IndexVerdict is how likely a URL is to appear in search results.
+type IndexVerdict string
+
+const (
+	// IndexIndexed means an IndexChecker found the URL in a search index.
+	IndexIndexed IndexVerdict = "indexed"
+	// IndexLikely means the URL is crawlable, indexable and in a sitemap or linked from a crawled page.
+	IndexLikely IndexVerdict = "likely"
+	// IndexPossible means nothing prevents indexing, or only some major crawlers are kept out.
+	IndexPossible IndexVerdict = "possible"
+	// IndexUnlikely means every major crawler is disallowed or the canonical URL points elsewhere.
+	IndexUnlikely IndexVerdict = "unlikely"
+	// IndexExcluded means every major crawler can fetch the URL and is told not to index it.
+	IndexExcluded IndexVerdict = "excluded"
+)
+
+// Indexability is the crawlability and indexability assessment of a result.
+type Indexability struct {
+	Verdict    IndexVerdict `json:"verdict"`
+	Reasons    []string     `json:"reasons,omitempty"`
+	Disallowed []string     `json:"robots_disallowed,omitempty"`
+	NoIndex    []string     `json:"noindex,omitempty"`
+	Canonical  string       `json:"canonical,omitempty"`
+	InSitemap  bool         `json:"in_sitemap"`
+	Linked     bool         `json:"linked"`
+
+	// analysisReasons are withdrawn from the analysis when a search index shows the URL.
+	analysisReasons []string
+}
+
+// majorCrawlers are the robots.txt product tokens of the search engine crawlers that matter.
+var majorCrawlers = []string{"googlebot", "bingbot", "duckduckbot", "yandex", "baiduspider", "applebot"}
+
+// robotsValueDirectives take a value after a colon, so "name: value" is not an agent prefix.
+var robotsValueDirectives = map[string]bool{
+	"unavailable_after": true, "max-snippet": true, "max-image-preview": true, "max-video-preview": true,
+}
+
+var (
+	metaTagRe    = regexp.MustCompile(`(?is)<meta\b[^>]*>`)
+	linkTagRe    = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	nameAttr     = regexp.MustCompile(`(?is)\bname\s*=\s*["']?([^"'\s>]+)`)
+	relAttr      = regexp.MustCompile(`(?is)\brel\s*=\s*["']?([^"'>]+)`)
+	hrefAttr     = regexp.MustCompile(`(?is)\bhref\s*=\s*["']?([^"'\s>]+)`)
+	linkHeaderRe = regexp.MustCompile(`<([^>]*)>\s*;[^,]*\brel\s*=\s*"?canonical\b`)
+)
+
+// assessIndexability returns nil for anything but 2xx, which search engines do not index.
+func assessIndexability(rr *RequestResult, robots *discover.RobotsFile) *Indexability {
+	if rr.StatusCode < 200 || rr.StatusCode >= 300 {
+		return nil
+	}
+	ix := &Indexability{
+		InSitemap: containsSource(rr.DiscoverySources, SourceSitemap),
+		Linked:    containsSource(rr.DiscoverySources, SourceCrawler),
+	}
+
+	noindex := noIndexAgents(rr.Headers, rr.Snippet)
+	for _, c := range majorCrawlers {
+		if robots != nil && !robots.Group(c).Allowed(rr.Path) {
+			ix.Disallowed = append(ix.Disallowed, c)
+		}
+		if noindex[""] || noindex[c] {
+			ix.NoIndex = append(ix.NoIndex, c)
+		}
+	}
+	if c := canonicalURL(rr.Headers, rr.Snippet, rr.URL); c != "" && !sameURL(c, rr.URL) {
+		ix.Canonical = c
+	}
+
+	// A crawler kept out by robots.txt never sees the noindex, so only the others are excluded.
+	excluded := 0
+	for _, c := range ix.NoIndex {
+		if !containsString(ix.Disallowed, c) {
+			excluded++
+		}
+	}
+	allOut := len(ix.Disallowed)+excluded == len(majorCrawlers)
+
+	switch {
+	case excluded == len(majorCrawlers):
+		ix.Verdict = IndexExcluded
+		ix.Reasons = append(ix.Reasons, "noindex applies to all major crawlers")
+	case allOut && len(ix.Disallowed) > 0:
+		ix.Verdict = IndexUnlikely
+		ix.Reasons = append(ix.Reasons, "robots.txt disallows "+strings.Join(ix.Disallowed, ", "))
+		if len(ix.NoIndex) > 0 {
+			ix.Reasons = append(ix.Reasons, "noindex for "+strings.Join(ix.NoIndex, ", "))
+		}
+		if ix.InSitemap || ix.Linked {
+			ix.Reasons = append(ix.Reasons, "disallowed URLs that are referenced can still be indexed without content")
+		}
+	case ix.Canonical != "":
+		ix.Verdict = IndexUnlikely
+		ix.Reasons = append(ix.Reasons, "canonical URL points elsewhere: "+ix.Canonical)
+	case len(ix.Disallowed) > 0 || len(ix.NoIndex) > 0:
+		ix.Verdict = IndexPossible
+		if len(ix.Disallowed) > 0 {
+			ix.Reasons = append(ix.Reasons, "robots.txt disallows only "+strings.Join(ix.Disallowed, ", "))
+		}
+		if len(ix.NoIndex) > 0 {
+			ix.Reasons = append(ix.Reasons, "noindex only for "+strings.Join(ix.NoIndex, ", "))
+		}
+	case ix.InSitemap || ix.Linked:
+		ix.Verdict = IndexLikely
+		ix.Reasons = append(ix.Reasons, "crawlable and indexable")
+	default:
+		ix.Verdict = IndexPossible
+		ix.Reasons = append(ix.Reasons, "crawlable and indexable but not advertised or linked")
+	}
+	if ix.InSitemap {
+		ix.Reasons = append(ix.Reasons, "listed in a sitemap")
+	}
+	if ix.Linked {
+		ix.Reasons = append(ix.Reasons, "linked from a crawled page")
+	}
+	return ix
+}
+
+// applyIndexability downgrades excluded results one level and unlikely ones from High to Medium.
+func applyIndexability(ix *Indexability, a *Analysis, flags *analysisFlags) {
+	if len(ix.NoIndex) > 0 {
+		flags.NoIndex = true
+	}
+	ix.analysisReasons = []string{"indexability " + string(ix.Verdict) + ": " + strings.Join(ix.Reasons, "; ")}
+	if !flags.ConfirmedSecret && !flags.DirectoryListing {
+		before := a.Severity
+		switch {
+		case a.Severity == SeverityHigh && (ix.Verdict == IndexExcluded || ix.Verdict == IndexUnlikely):
+			a.Severity = SeverityMedium
+		case a.Severity == SeverityMedium && ix.Verdict == IndexExcluded:
+			a.Severity = SeverityLow
+		}
+		if a.Severity != before {
+			ix.analysisReasons = append(ix.analysisReasons, "severity downgraded: indexing "+string(ix.Verdict))
+		}
+	}
+	a.Reasons = append(a.Reasons, ix.analysisReasons...)
+}
+
+// noIndexAgents keys crawlers by product token, "" meaning all. An X-Robots-Tag agent prefix
+// ("googlebot: noindex") scopes the directives up to the next prefix.
+func noIndexAgents(headers map[string][]string, html string) map[string]bool {
+	out := make(map[string]bool)
+	for k, vals := range headers {
+		if !strings.EqualFold(k, "X-Robots-Tag") {
+			continue
+		}
+		for _, v := range vals {
+			agent := ""
+			for _, t := range strings.Split(strings.ToLower(v), ",") {
+				t = strings.TrimSpace(t)
+				if name, rest, ok := strings.Cut(t, ":"); ok {
+					name = strings.TrimSpace(name)
+					if !robotsValueDirectives[name] && !strings.ContainsAny(name, " \t") {
+						agent, t = name, strings.TrimSpace(rest)
+					}
+				}
+				if t == "noindex" || t == "none" {
+					out[agent] = true
+				}
+			}
+		}
+	}
+
+	for _, m := range metaTagRe.FindAllString(html, -1) {
+		nm := nameAttr.FindStringSubmatch(m)
+		if nm == nil {
+			continue
+		}
+		agent := strings.ToLower(nm[1])
+		if agent == "robots" {
+			agent = ""
+		} else if !containsString(majorCrawlers, agent) {
+			continue
+		}
+		if cm := contentAttr.FindStringSubmatch(m); cm != nil && hasNoIndexDirective(cm[1]) {
+			out[agent] = true
+		}
+	}
+	return out
+}
+
+// canonicalURL returns the canonical URL from a Link header or <link> tag, resolved against pageURL.
+func canonicalURL(headers map[string][]string, html, pageURL string) string {
+	var href string
+	for k, vals := range headers {
+		if !strings.EqualFold(k, "Link") {
+			continue
+		}
+		for _, v := range vals {
+			if m := linkHeaderRe.FindStringSubmatch(v); m != nil {
+				href = m[1]
+			}
+		}
+	}
+	if href == "" {
+		for _, l := range linkTagRe.FindAllString(html, -1) {
+			rel := relAttr.FindStringSubmatch(l)
+			if rel == nil || !containsString(strings.Fields(strings.ToLower(rel[1])), "canonical") {
+				continue
+			}
+			if h := hrefAttr.FindStringSubmatch(l); h != nil {
+				href = h[1]
+				break
+			}
+		}
+	}
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// sameURL compares URLs ignoring scheme and host case, fragments and a trailing slash.
+func sameURL(a, b string) bool {
+	ua, err1 := url.Parse(a)
+	ub, err2 := url.Parse(b)
+	if err1 != nil || err2 != nil {
+		return a == b
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host) &&
+		strings.TrimSuffix(ua.EscapedPath(), "/") == strings.TrimSuffix(ub.EscapedPath(), "/") &&
+		ua.RawQuery == ub.RawQuery
+}
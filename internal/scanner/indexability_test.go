@@ -0,0 +1,215 @@
+package scanner
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Jason-0902/wdf/internal/discover"
+)
+
+func TestNoIndexAgents(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		html   string
+		want   []string
+	}{
+		{name: "all crawlers", header: []string{"noindex"}, want: []string{""}},
+		{name: "none", header: []string{"None"}, want: []string{""}},
+		{name: "other directives", header: []string{"nofollow, noarchive"}},
+		{name: "agent prefix", header: []string{"googlebot: noindex"}, want: []string{"googlebot"}},
+		{name: "prefix scopes later directives", header: []string{"googlebot: nofollow, noindex"}, want: []string{"googlebot"}},
+		{name: "next prefix ends the scope", header: []string{"bingbot: noindex, googlebot: nofollow"}, want: []string{"bingbot"}},
+		{name: "scope ends with the header value", header: []string{"googlebot: nofollow", "noindex"}, want: []string{""}},
+		{name: "unavailable_after is not an agent", header: []string{"unavailable_after: 25 Jun 2010 15:00:00 PST, noindex"}, want: []string{""}},
+		{name: "unavailable_after inside an agent scope", header: []string{"googlebot: unavailable_after: 2010-06-25, noindex"}, want: []string{"googlebot"}},
+		{name: "max-snippet is not an agent", header: []string{"max-snippet: 20, noindex"}, want: []string{""}},
+		{name: "meta robots", html: `<meta name="robots" content="noindex, follow">`, want: []string{""}},
+		{name: "meta major crawler", html: `<META NAME="Googlebot" CONTENT="none">`, want: []string{"googlebot"}},
+		{name: "meta other crawler", html: `<meta name="otherbot" content="noindex">`},
+		{name: "meta other name", html: `<meta name="description" content="noindex">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers map[string][]string
+			if tt.header != nil {
+				headers = map[string][]string{"x-robots-tag": tt.header}
+			}
+			var got []string
+			for agent := range noIndexAgents(headers, tt.html) {
+				got = append(got, agent)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("agents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	const page = "https://example.com/admin/"
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		html    string
+		want    string
+	}{
+		{name: "none"},
+		{name: "link header", headers: map[string][]string{"Link": {`<https://example.com/other>; rel="canonical"`}}, want: "https://example.com/other"},
+		{name: "link header wins", headers: map[string][]string{"Link": {`</h>; rel=canonical`}}, html: `<link rel="canonical" href="/t">`, want: "https://example.com/h"},
+		{name: "relative tag", html: `<link href="../login" rel="Canonical">`, want: "https://example.com/login"},
+		{name: "other rel", html: `<link rel="alternate" href="/fr/">`},
+	}
+	for _, tt := range tests {
+		if got := canonicalURL(tt.headers, tt.html, page); got != tt.want {
+			t.Errorf("%s: canonicalURL = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssessIndexability(t *testing.T) {
+	robots := func(s string) *discover.RobotsFile {
+		f, err := discover.ParseRobots(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	tests := []struct {
+		name       string
+		rr         RequestResult
+		robots     *discover.RobotsFile
+		verdict    IndexVerdict
+		reasons    []string
+		disallowed int
+		noindex    int
+	}{
+		{
+			name:    "noindex for everyone",
+			rr:      RequestResult{Headers: map[string][]string{"X-Robots-Tag": {"noindex"}}},
+			verdict: IndexExcluded,
+			reasons: []string{"noindex applies to all major crawlers"},
+			noindex: 6,
+		},
+		{
+			name:       "disallowed for everyone and referenced",
+			rr:         RequestResult{DiscoverySources: []DiscoverySource{SourceSitemap}},
+			robots:     robots("User-agent: *\nDisallow: /admin\n"),
+			verdict:    IndexUnlikely,
+			reasons:    []string{"robots.txt disallows " + strings.Join(majorCrawlers, ", "), "disallowed URLs that are referenced can still be indexed without content", "listed in a sitemap"},
+			disallowed: 6,
+		},
+		{
+			// Googlebot never sees the noindex, but it is kept out all the same.
+			name:       "disallowed for some, noindex for the rest",
+			rr:         RequestResult{Headers: map[string][]string{"X-Robots-Tag": {"noindex"}}},
+			robots:     robots("User-agent: googlebot\nDisallow: /\n"),
+			verdict:    IndexUnlikely,
+			reasons:    []string{"robots.txt disallows googlebot", "noindex for " + strings.Join(majorCrawlers, ", ")},
+			disallowed: 1,
+			noindex:    6,
+		},
+		{
+			name:    "canonical elsewhere",
+			rr:      RequestResult{Snippet: `<link rel="canonical" href="/login">`},
+			verdict: IndexUnlikely,
+			reasons: []string{"canonical URL points elsewhere: https://example.com/login"},
+		},
+		{
+			name:    "canonical to itself",
+			rr:      RequestResult{Snippet: `<link rel="canonical" href="https://EXAMPLE.com/admin">`},
+			verdict: IndexPossible,
+			reasons: []string{"crawlable and indexable but not advertised or linked"},
+		},
+		{
+			name:       "disallowed for one crawler",
+			rr:         RequestResult{DiscoverySources: []DiscoverySource{SourceCrawler}},
+			robots:     robots("User-agent: bingbot\nDisallow: /admin/\n"),
+			verdict:    IndexPossible,
+			reasons:    []string{"robots.txt disallows only bingbot", "linked from a crawled page"},
+			disallowed: 1,
+		},
+		{
+			name:    "noindex for one crawler",
+			rr:      RequestResult{Headers: map[string][]string{"X-Robots-Tag": {"googlebot: noindex"}}},
+			verdict: IndexPossible,
+			reasons: []string{"noindex only for googlebot"},
+			noindex: 1,
+		},
+		{
+			name:    "advertised",
+			rr:      RequestResult{DiscoverySources: []DiscoverySource{SourceSitemap, SourceCrawler}},
+			robots:  robots("User-agent: *\nDisallow: /private/\n"),
+			verdict: IndexLikely,
+			reasons: []string{"crawlable and indexable", "listed in a sitemap", "linked from a crawled page"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := tt.rr
+			rr.URL, rr.Path, rr.StatusCode = "https://example.com/admin/", "/admin/", 200
+			ix := assessIndexability(&rr, tt.robots)
+			if ix == nil {
+				t.Fatal("no assessment for a 200")
+			}
+			if ix.Verdict != tt.verdict || !reflect.DeepEqual(ix.Reasons, tt.reasons) {
+				t.Errorf("verdict %s, reasons %q\nwant %s, %q", ix.Verdict, ix.Reasons, tt.verdict, tt.reasons)
+			}
+			if len(ix.Disallowed) != tt.disallowed || len(ix.NoIndex) != tt.noindex {
+				t.Errorf("disallowed %q, noindex %q", ix.Disallowed, ix.NoIndex)
+			}
+		})
+	}
+
+	for _, code := range []int{301, 404, 500} {
+		rr := RequestResult{URL: "https://example.com/admin/", Path: "/admin/", StatusCode: code}
+		if ix := assessIndexability(&rr, nil); ix != nil {
+			t.Errorf("status %d assessed: %+v", code, ix)
+		}
+	}
+}
+
+func TestApplyIndexability(t *testing.T) {
+	tests := []struct {
+		verdict IndexVerdict
+		before  Severity
+		flags   analysisFlags
+		want    Severity
+	}{
+		{IndexExcluded, SeverityHigh, analysisFlags{}, SeverityMedium},
+		{IndexExcluded, SeverityMedium, analysisFlags{}, SeverityLow},
+		{IndexExcluded, SeverityLow, analysisFlags{}, SeverityLow},
+		{IndexUnlikely, SeverityHigh, analysisFlags{}, SeverityMedium},
+		{IndexUnlikely, SeverityMedium, analysisFlags{}, SeverityMedium},
+		{IndexPossible, SeverityHigh, analysisFlags{}, SeverityHigh},
+		{IndexLikely, SeverityHigh, analysisFlags{}, SeverityHigh},
+		{IndexExcluded, SeverityHigh, analysisFlags{ConfirmedSecret: true}, SeverityHigh},
+		{IndexExcluded, SeverityMedium, analysisFlags{DirectoryListing: true}, SeverityMedium},
+	}
+	for _, tt := range tests {
+		ix := &Indexability{Verdict: tt.verdict, Reasons: []string{"r"}}
+		a := Analysis{Severity: tt.before}
+		flags := tt.flags
+		applyIndexability(ix, &a, &flags)
+		if a.Severity != tt.want {
+			t.Errorf("%s %s (%+v): severity %s, want %s", tt.verdict, tt.before, tt.flags, a.Severity, tt.want)
+		}
+		downgraded := len(a.Reasons) == 2
+		if downgraded != (tt.want != tt.before) || a.Reasons[0] != "indexability "+string(tt.verdict)+": r" {
+			t.Errorf("%s %s: reasons %q", tt.verdict, tt.before, a.Reasons)
+		}
+		if flags.NoIndex {
+			t.Errorf("%s: noindex flag set without noindex crawlers", tt.verdict)
+		}
+	}
+
+	ix := &Indexability{Verdict: IndexPossible, NoIndex: []string{"googlebot"}}
+	var flags analysisFlags
+	applyIndexability(ix, &Analysis{}, &flags)
+	if !flags.NoIndex {
+		t.Error("noindex flag not set")
+	}
+}
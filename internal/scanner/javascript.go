@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// JSAsset summarises the secrets and source map of a JavaScript bundle.
+type JSAsset struct {
+	Bytes     int        `json:"bytes"`
+	Truncated bool       `json:"truncated,omitempty"`
+	Secrets   []string   `json:"secrets,omitempty"`
+	Keywords  []string   `json:"keywords,omitempty"`
+	SourceMap *SourceMap `json:"source_map,omitempty"`
+}
+
+// SourceMap describes a requested source map; Referenced is false when <bundle>.map was guessed.
+type SourceMap struct {
+	URL            string   `json:"url"`
+	Inline         bool     `json:"inline,omitempty"`
+	Referenced     bool     `json:"referenced"`
+	Served         bool     `json:"served"`
+	StatusCode     int      `json:"status_code,omitempty"`
+	SourceCount    int      `json:"source_count,omitempty"`
+	Sources        []string `json:"sources,omitempty"`
+	SourcesContent bool     `json:"sources_content,omitempty"`
+	Secrets        []string `json:"secrets,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+const (
+	maxJSAssetBytes   = 2 << 20
+	maxSourceMapBytes = 8 << 20
+	maxMapSources     = 200
+)
+
+var sourceMappingURLRe = regexp.MustCompile(`(?m)^[ \t]*//[#@][ \t]*sourceMappingURL=([^\s'"]+)[ \t]*$`)
+
+func isJavaScript(p string, headers map[string][]string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".js", ".mjs", ".cjs":
+		return true
+	}
+	ct := strings.ToLower(firstHeader(headers, "Content-Type"))
+	return strings.Contains(ct, "javascript") || strings.Contains(ct, "ecmascript")
+}
+
+// inspectJavaScript considers only same-origin and inline source maps.
+func inspectJavaScript(ctx context.Context, client *http.Client, cfg Config, rs RuleSet, jsURL string) *JSAsset {
+	st, hdr, body, err := fetchRaw(ctx, client, cfg, jsURL, nil, maxJSAssetBytes)
+	if err != nil || st != http.StatusOK {
+		return nil
+	}
+	js := &JSAsset{Bytes: len(body), Truncated: len(body) >= maxJSAssetBytes}
+	js.Secrets, js.Keywords = matchPatterns(rs, string(body))
+
+	base, err := url.Parse(jsURL)
+	if err != nil {
+		return js
+	}
+	ref := firstHeader(hdr, "SourceMap")
+	if ref == "" {
+		ref = firstHeader(hdr, "X-SourceMap")
+	}
+	if ref == "" {
+		// The comment is normally the last line, so search from the end.
+		if all := sourceMappingURLRe.FindAllStringSubmatch(string(body), -1); len(all) > 0 {
+			ref = all[len(all)-1][1]
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(ref, "data:"):
+		sm := &SourceMap{URL: "inline", Inline: true, Referenced: true, Served: true}
+		if raw, ok := decodeDataURI(ref); ok {
+			parseSourceMap(rs, raw, sm)
+		} else {
+			sm.Error = "undecodable inline source map"
+		}
+		js.SourceMap = sm
+	case ref != "":
+		if u := resolveSameHost(base, ref); u != "" {
+			js.SourceMap = fetchSourceMap(ctx, client, cfg, rs, u, true)
+		}
+	default:
+		guess := *base
+		guess.RawQuery = ""
+		guess.Path += ".map"
+		if sm := fetchSourceMap(ctx, client, cfg, rs, guess.String(), false); sm.Served {
+			js.SourceMap = sm
+		}
+	}
+	return js
+}
+
+func fetchSourceMap(ctx context.Context, client *http.Client, cfg Config, rs RuleSet, mapURL string, referenced bool) *SourceMap {
+	sm := &SourceMap{URL: mapURL, Referenced: referenced}
+	st, _, body, err := fetchRaw(ctx, client, cfg, mapURL, map[string]string{"Accept": "application/json,*/*"}, maxSourceMapBytes)
+	sm.StatusCode = st
+	if err != nil {
+		sm.Error = err.Error()
+		return sm
+	}
+	if st == http.StatusOK {
+		parseSourceMap(rs, body, sm)
+	}
+	return sm
+}
+
+// parseSourceMap requires a version 3 map so 200 HTML fallbacks are not mistaken for one.
+func parseSourceMap(rs RuleSet, body []byte, sm *SourceMap) {
+	var m struct {
+		Version        int       `json:"version"`
+		Sources        []string  `json:"sources"`
+		SourcesContent []*string `json:"sourcesContent"`
+	}
+	if json.Unmarshal(body, &m) != nil || m.Version != 3 {
+		sm.Served = false
+		return
+	}
+	sm.Served = true
+	sm.SourceCount = len(m.Sources)
+	sources := append([]string(nil), m.Sources...)
+	sort.Strings(sources)
+	if len(sources) > maxMapSources {
+		sources = sources[:maxMapSources]
+	}
+	sm.Sources = sources
+
+	secrets := make(map[string]struct{})
+	for _, c := range m.SourcesContent {
+		if c == nil || *c == "" {
+			continue
+		}
+		sm.SourcesContent = true
+		high, _ := matchPatterns(rs, *c)
+		for _, s := range high {
+			secrets[s] = struct{}{}
+		}
+	}
+	sm.Secrets = sortedKeys(secrets)
+}
+
+// matchPatterns separates secrets from keywords; bundles are full of words like "password".
+func matchPatterns(rs RuleSet, s string) (secrets, keywords []string) {
+	for _, p := range rs.Patterns {
+		if p.Re == nil || p.Name == "Directory listing" || !p.Re.MatchString(s) {
+			continue
+		}
+		if p.Severity == SeverityHigh {
+			secrets = append(secrets, p.Name)
+		} else {
+			keywords = append(keywords, p.Name)
+		}
+	}
+	return secrets, keywords
+}
+
+func decodeDataURI(uri string) ([]byte, bool) {
+	i := strings.IndexByte(uri, ',')
+	if i < 0 {
+		return nil, false
+	}
+	meta, data := uri[5:i], uri[i+1:]
+	if strings.HasSuffix(meta, ";base64") {
+		b, err := base64.StdEncoding.DecodeString(data)
+		return b, err == nil
+	}
+	s, err := url.PathUnescape(data)
+	return []byte(s), err == nil
+}
+
+func applyJSEvidence(js *JSAsset, a *Analysis, flags *analysisFlags) {
+	secrets := append([]string(nil), js.Secrets...)
+	if sm := js.SourceMap; sm != nil && sm.Served {
+		flags.SourceMap = true
+		a.Interesting = true
+		if severityRank(a.Severity) < severityRank(SeverityMedium) {
+			a.Severity = SeverityMedium
+		}
+		reason := "source map exposed"
+		if !sm.Inline {
+			reason += ": " + sm.URL
+		}
+		a.Reasons = append(a.Reasons, reason)
+		if sm.SourcesContent {
+			a.Reasons = append(a.Reasons, "source map embeds original source code")
+		}
+		secrets = append(secrets, sm.Secrets...)
+	}
+	if len(secrets) > 0 {
+		secrets = dedupeStrings(secrets)
+		a.Interesting = true
+		a.Severity = SeverityHigh
+		flags.ConfirmedSecret = true
+		a.Patterns = dedupeStrings(append(a.Patterns, secrets...))
+		a.Reasons = append(a.Reasons, "JavaScript contains secrets: "+joinLimited(secrets, 5))
+	}
+}
@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DirectoryListing holds the entries parsed from an autoindex page.
+type DirectoryListing struct {
+	Server    string         `json:"server,omitempty"`
+	Entries   []ListingEntry `json:"entries,omitempty"`
+	Total     int            `json:"total"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Enqueued  []string       `json:"enqueued,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type ListingEntry struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Dir      bool   `json:"dir,omitempty"`
+	Size     string `json:"size,omitempty"`
+	Modified string `json:"modified,omitempty"`
+}
+
+const (
+	maxListingBytes   = 1 << 20
+	maxListingEntries = 1000
+)
+
+var (
+	listingAnchorRe = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))[^>]*>(.*?)</a>`)
+	listingRowRe    = regexp.MustCompile(`(?i)<tr[\s>]|<br\s*/?>|\n`)
+	listingTagRe    = regexp.MustCompile(`(?s)<[^>]*>`)
+	listingDateRe   = regexp.MustCompile(`(?i)\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(?::\d{2})?` +
+		`|\d{2}-[a-z]{3}-\d{4} \d{2}:\d{2}(?::\d{2})?` +
+		`|\d{4}-[a-z]{3}-\d{2} \d{2}:\d{2}(?::\d{2})?` +
+		`|(?:[a-z]+, )?[a-z]+ \d{1,2}, \d{4}\s+\d{1,2}:\d{2}(?: [ap]m)?` +
+		`|\d{1,2}/\d{1,2}/\d{4}\s+\d{1,2}:\d{2}(?: [ap]m)?`)
+	listingSizeRe = regexp.MustCompile(`(?i)^(?:\d+(?:\.\d+)?[kmgtp]?b?|-|<dir>)$`)
+)
+
+// inspectDirectoryListing refetches the full page, since the analysed snippet is truncated.
+func inspectDirectoryListing(ctx context.Context, client *http.Client, cfg Config, j job, fullURL string) *DirectoryListing {
+	status, hdr, body, err := fetchRaw(ctx, client, cfg, fullURL, nil, maxListingBytes)
+	if err != nil {
+		return &DirectoryListing{Error: err.Error()}
+	}
+	if status != http.StatusOK {
+		return nil
+	}
+
+	base, err := url.Parse(fullURL)
+	if err != nil {
+		return &DirectoryListing{Error: err.Error()}
+	}
+	// Listings describe a directory; resolve relative links as if the URL ended in a slash.
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	page := string(body)
+	l := parseDirectoryListing(page, base)
+	l.Server = listingServer(hdr.Get("Server"), page)
+	l.Truncated = l.Truncated || len(body) >= maxListingBytes
+
+	if !cfg.FollowListings {
+		return l
+	}
+	for _, e := range l.Entries {
+		sensitive := !e.Dir && isSensitiveFileName(e.Name)
+		if j.state.enqueue(pathPlan{Path: e.Path, IsSensitive: sensitive, Critical: sensitive, Source: SourceListing, Referrer: fullURL, Depth: j.depth + 1}) {
+			l.Enqueued = append(l.Enqueued, e.Path)
+		}
+	}
+	return l
+}
+
+// parseDirectoryListing reads one link plus an optional date and size per row of an autoindex page.
+func parseDirectoryListing(page string, base *url.URL) *DirectoryListing {
+	l := &DirectoryListing{}
+	seen := make(map[string]struct{})
+
+	for _, row := range listingRowRe.Split(page, -1) {
+		m := listingAnchorRe.FindStringSubmatchIndex(row)
+		if m == nil {
+			continue
+		}
+		href := ""
+		for g := 1; g <= 3; g++ {
+			if m[2*g] >= 0 {
+				href = html.UnescapeString(row[m[2*g]:m[2*g+1]])
+				break
+			}
+		}
+		name := strings.TrimSpace(html.UnescapeString(listingTagRe.ReplaceAllString(row[m[8]:m[9]], "")))
+
+		p, ok := listingEntryPath(base, href)
+		if !ok {
+			continue
+		}
+		if _, dup := seen[p]; dup {
+			continue
+		}
+		seen[p] = struct{}{}
+
+		rest := html.UnescapeString(listingTagRe.ReplaceAllString(row[:m[0]]+" "+row[m[1]:], " "))
+		e := ListingEntry{Name: strings.TrimSuffix(name, "/"), Path: p, Dir: strings.HasSuffix(href, "/")}
+		if loc := listingDateRe.FindStringIndex(rest); loc != nil {
+			e.Modified = strings.Join(strings.Fields(rest[loc[0]:loc[1]]), " ")
+			for _, tok := range strings.Fields(rest[loc[1]:]) {
+				if listingSizeRe.MatchString(tok) {
+					if strings.EqualFold(tok, "<dir>") {
+						e.Dir = true
+					} else if tok != "-" {
+						e.Size = tok
+					}
+					break
+				}
+			}
+		}
+		if e.Name == "" {
+			e.Name = strings.TrimPrefix(p[strings.LastIndex(strings.TrimSuffix(p, "/"), "/"):], "/")
+		}
+
+		l.Total++
+		if len(l.Entries) < maxListingEntries {
+			l.Entries = append(l.Entries, e)
+		} else {
+			l.Truncated = true
+		}
+	}
+	return l
+}
+
+// listingEntryPath keeps only links strictly below the listed directory.
+func listingEntryPath(base *url.URL, href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "?") || strings.HasPrefix(href, "#") {
+		return "", false
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	u := base.ResolveReference(ref)
+	if !strings.EqualFold(u.Host, base.Host) || u.Scheme != base.Scheme {
+		return "", false
+	}
+	if !strings.HasPrefix(u.Path, base.Path) || len(u.Path) <= len(base.Path) {
+		return "", false
+	}
+	return normalizePath(u.Path)
+}
+
+func listingServer(serverHeader, page string) string {
+	sh := strings.ToLower(serverHeader)
+	lp := strings.ToLower(page)
+	switch {
+	case strings.Contains(sh, "nginx"), strings.Contains(lp, `<hr><pre><a href="../">`):
+		return "nginx"
+	case strings.Contains(sh, "lighttpd"), strings.Contains(lp, `class="n"`):
+		return "lighttpd"
+	case strings.Contains(sh, "microsoft-iis"), strings.Contains(lp, "[to parent directory]"):
+		return "iis"
+	case strings.Contains(sh, "apache"), strings.Contains(lp, "?c=n;o=d"), strings.Contains(lp, "<address>apache"):
+		return "apache"
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const apacheListing = `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">
+<html><head><title>Index of /files</title></head><body><h1>Index of /files</h1>
+<table>
+<tr><th><a href="?C=N;O=D">Name</a></th><th><a href="?C=M;O=A">Last modified</a></th><th><a href="?C=S;O=A">Size</a></th></tr>
+<tr><td><a href="/">Parent Directory</a></td><td>&nbsp;</td><td align="right">  - </td></tr>
+<tr><td><a href="backup.sql">backup.sql</a></td><td align="right">2024-03-01 12:30  </td><td align="right">4.2M</td></tr>
+<tr><td><a href="img/">img/</a></td><td align="right">2024-02-11 08:00  </td><td align="right">  - </td></tr>
+<tr><td><a href="a%26b.txt">a&amp;b.txt</a></td><td align="right">2023-12-31 23:59  </td><td align="right">120 </td></tr>
+</table>
+<address>Apache/2.4.57 (Debian) Server at example.com Port 80</address>
+</body></html>`
+
+const nginxListing = `<html>
+<head><title>Index of /files/</title></head>
+<body>
+<h1>Index of /files/</h1><hr><pre><a href="../">../</a>
+<a href="conf/">conf/</a>                                              01-Mar-2024 10:00                   -
+<a href="id_rsa">id_rsa</a>                                             02-Mar-2024 11:15                1679
+<a href="https://evil.example/x">external</a>                            02-Mar-2024 11:15                   1
+</pre><hr></body>
+</html>`
+
+const iisListing = `<html><head><title>example.com - /files/</title></head><body><H1>example.com - /files/</H1><hr>
+
+<pre><A HREF="/">[To Parent Directory]</A><br><br>  3/1/2024  9:05 AM        &lt;dir&gt; <A HREF="/files/logs/">logs</A><br>  3/2/2024 10:40 PM         2048 <A HREF="/files/web.config">web.config</A><br></pre><hr></body></html>`
+
+func TestParseDirectoryListing(t *testing.T) {
+	base, _ := url.Parse("http://example.com/files/")
+	tests := []struct {
+		name   string
+		page   string
+		header string
+		server string
+		want   []ListingEntry
+	}{
+		{
+			name:   "apache",
+			page:   apacheListing,
+			server: "apache",
+			want: []ListingEntry{
+				{Name: "backup.sql", Path: "/files/backup.sql", Size: "4.2M", Modified: "2024-03-01 12:30"},
+				{Name: "img", Path: "/files/img", Dir: true, Modified: "2024-02-11 08:00"},
+				{Name: "a&b.txt", Path: "/files/a&b.txt", Size: "120", Modified: "2023-12-31 23:59"},
+			},
+		},
+		{
+			name:   "nginx",
+			page:   nginxListing,
+			server: "nginx",
+			want: []ListingEntry{
+				{Name: "conf", Path: "/files/conf", Dir: true, Modified: "01-Mar-2024 10:00"},
+				{Name: "id_rsa", Path: "/files/id_rsa", Size: "1679", Modified: "02-Mar-2024 11:15"},
+			},
+		},
+		{
+			name:   "iis",
+			page:   iisListing,
+			header: "Microsoft-IIS/10.0",
+			server: "iis",
+			want: []ListingEntry{
+				{Name: "logs", Path: "/files/logs", Dir: true, Modified: "3/1/2024 9:05 AM"},
+				{Name: "web.config", Path: "/files/web.config", Size: "2048", Modified: "3/2/2024 10:40 PM"},
+			},
+		},
+		{name: "no links", page: "<html><body>Forbidden</body></html>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := parseDirectoryListing(tt.page, base)
+			if !reflect.DeepEqual(l.Entries, tt.want) {
+				t.Errorf("entries = %+v\nwant %+v", l.Entries, tt.want)
+			}
+			if l.Total != len(tt.want) {
+				t.Errorf("total = %d, want %d", l.Total, len(tt.want))
+			}
+			if got := listingServer(tt.header, tt.page); got != tt.server {
+				t.Errorf("server = %q, want %q", got, tt.server)
+			}
+		})
+	}
+}
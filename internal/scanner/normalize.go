@@ -3,11 +3,20 @@ package scanner
 import (
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 )
 
+// normalizePath cleans the path component of p. A query string, if present, is kept verbatim.
 func normalizePath(p string) (string, bool) {
 	p = strings.TrimSpace(p)
+	query := ""
+	if i := strings.IndexByte(p, '?'); i >= 0 {
+		p, query = p[:i], p[i+1:]
+		if p == "" && query != "" {
+			p = "/"
+		}
+	}
 	if p == "" {
 		return "", false
 	}
@@ -25,6 +34,9 @@ func normalizePath(p string) (string, bool) {
 	if !strings.HasPrefix(cp, "/") {
 		cp = "/" + cp
 	}
+	if query != "" {
+		cp += "?" + query
+	}
 	return cp, true
 }
 
@@ -45,6 +57,44 @@ func normalizeURLToSameOriginPath(base *url.URL, raw string) (string, bool) {
 	if p == "" {
 		p = "/"
 	}
-	return normalizePath(p)
+	n, ok := normalizePath(p)
+	if !ok {
+		return "", false
+	}
+	if q := meaningfulQuery(u.Query()); q != "" {
+		n += "?" + q
+	}
+	return n, true
+}
+
+// trackingParams carry no meaning for the server's response and are dropped from discovered URLs.
+var trackingParams = map[string]bool{"fbclid": true, "gclid": true, "msclkid": true, "dclid": true, "yclid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true}
+
+// meaningfulQuery re-encodes q with parameters sorted by name, without tracking parameters.
+func meaningfulQuery(q url.Values) string {
+	for k := range q {
+		if trackingParams[strings.ToLower(k)] || strings.HasPrefix(strings.ToLower(k), "utm_") {
+			delete(q, k)
+		}
+	}
+	return q.Encode()
+}
+
+// planKey is the path plus its sorted parameter names: /item?id=1 and /item?id=2 are scanned once.
+func planKey(p string) string {
+	base, query, ok := strings.Cut(p, "?")
+	if !ok {
+		return p
+	}
+	q, err := url.ParseQuery(query)
+	if err != nil || len(q) == 0 {
+		return base
+	}
+	names := make([]string, 0, len(q))
+	for k := range q {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return base + "?" + strings.Join(names, "&")
 }
 
@@ -0,0 +1,323 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ObservabilityExposure describes a monitoring endpoint whose response format was recognised; Items
+// holds what it enumerates, depending on Kind.
+type ObservabilityExposure struct {
+	Kind        string            `json:"kind"`
+	Version     string            `json:"version,omitempty"`
+	GoVersion   string            `json:"go_version,omitempty"`
+	BuildInfo   map[string]string `json:"build_info,omitempty"`
+	Items       []string          `json:"items,omitempty"`
+	Total       int               `json:"total,omitempty"`
+	SecretNames []string          `json:"secret_names,omitempty"`
+}
+
+const (
+	maxObservabilityBytes = 1 << 20
+	maxObservabilityItems = 50
+)
+
+var observabilitySeverity = map[string]Severity{
+	"pprof":         SeverityHigh,
+	"expvar":        SeverityHigh,
+	"jolokia":       SeverityHigh,
+	"elasticsearch": SeverityHigh,
+	"prometheus":    SeverityMedium,
+	"kibana":        SeverityMedium,
+	"grafana":       SeverityMedium,
+	"health":        SeverityMedium,
+}
+
+var (
+	promHelpRe       = regexp.MustCompile(`(?m)^# (?:HELP|TYPE) ([a-zA-Z_:][a-zA-Z0-9_:]*) `)
+	promGoInfoRe     = regexp.MustCompile(`(?m)^go_info\{[^}]*version="([^"]+)"`)
+	promBuildInfoRe  = regexp.MustCompile(`(?m)^([a-zA-Z_:][a-zA-Z0-9_:]*_build_info)\{([^}]*)\}`)
+	promLabelRe      = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"`)
+	pprofProfileRe   = regexp.MustCompile(`href=['"]?([a-z_]+)\?debug=1`)
+	esCatIndexRe     = regexp.MustCompile(`(?m)^(?:green|yellow|red)\s+(?:open|close)\s+(\S+)\s+\S+\s+\d+\s+\d+`)
+	esCatNodeRe      = regexp.MustCompile(`(?m)^(\d{1,3}(?:\.\d{1,3}){3}|[0-9a-fA-F:]+:[0-9a-fA-F:]*)\s+(?:\d+\s+){2}.*?\s(\S+)\s*$`)
+	healthStatusVals = map[string]bool{"up": true, "down": true, "ok": true, "pass": true, "fail": true, "warn": true, "healthy": true, "unhealthy": true, "degraded": true, "out_of_service": true, "unknown": true}
+)
+
+// observabilityKind picks the parser from the path and snippet; the parser validates the full body.
+func observabilityKind(p, snippet string) string {
+	lp := strings.ToLower(p)
+	s := strings.TrimSpace(snippet)
+	switch base := path.Base(lp); {
+	case (base == "metrics" || base == "prometheus") && (strings.Contains(s, "# TYPE ") || strings.Contains(s, "# HELP ")):
+		return "prometheus"
+	case strings.HasSuffix(lp, "/debug/pprof") && strings.Contains(s, "debug=1"):
+		return "pprof"
+	case strings.HasSuffix(lp, "/debug/vars") && (strings.Contains(s, `"cmdline"`) || strings.Contains(s, `"memstats"`)):
+		return "expvar"
+	case (base == "health" || base == "healthz" || base == "readyz" || base == "livez") && strings.HasPrefix(s, "{") && strings.Contains(s, `"status"`):
+		return "health"
+	case strings.Contains(lp, "/jolokia") && strings.Contains(s, `"agent"`):
+		return "jolokia"
+	case strings.Contains(lp, "/_cat/"):
+		return "elasticsearch"
+	case strings.HasSuffix(lp, "/api/status") && strings.Contains(s, `"version"`):
+		return "kibana"
+	case strings.HasSuffix(lp, "/api/health") && strings.Contains(s, `"database"`):
+		return "grafana"
+	}
+	return ""
+}
+
+// inspectObservability fetches the full response and validates it against the format of kind.
+func inspectObservability(ctx context.Context, client *http.Client, cfg Config, fullURL, kind string) *ObservabilityExposure {
+	st, _, body, err := fetchRaw(ctx, client, cfg, fullURL, nil, maxObservabilityBytes)
+	if err != nil || st != http.StatusOK {
+		return nil
+	}
+	page := string(body)
+	switch kind {
+	case "prometheus":
+		return parsePrometheus(page)
+	case "pprof":
+		return parsePprofIndex(page)
+	case "expvar":
+		return parseExpvar(body)
+	case "health":
+		return parseHealth(body)
+	case "jolokia":
+		return parseJolokia(body)
+	case "elasticsearch":
+		return parseESCat(page)
+	case "kibana":
+		return parseKibanaStatus(body)
+	case "grafana":
+		return parseGrafanaHealth(body)
+	}
+	return nil
+}
+
+func parsePrometheus(page string) *ObservabilityExposure {
+	families := make(map[string]struct{})
+	for _, m := range promHelpRe.FindAllStringSubmatch(page, -1) {
+		families[m[1]] = struct{}{}
+	}
+	if len(families) == 0 {
+		return nil
+	}
+	x := &ObservabilityExposure{Kind: "prometheus", Total: len(families)}
+	if m := promGoInfoRe.FindStringSubmatch(page); m != nil {
+		x.GoVersion = m[1]
+	}
+	// Only the first build_info series is kept; exporters usually publish exactly one.
+	if m := promBuildInfoRe.FindStringSubmatch(page); m != nil {
+		x.BuildInfo = map[string]string{"metric": m[1]}
+		for _, l := range promLabelRe.FindAllStringSubmatch(m[2], -1) {
+			x.BuildInfo[l[1]] = l[2]
+		}
+		x.Version = x.BuildInfo["version"]
+		if x.GoVersion == "" {
+			x.GoVersion = x.BuildInfo["goversion"]
+		}
+	}
+	return x
+}
+
+func parsePprofIndex(page string) *ObservabilityExposure {
+	seen := make(map[string]struct{})
+	for _, m := range pprofProfileRe.FindAllStringSubmatch(page, -1) {
+		seen[m[1]] = struct{}{}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	x := &ObservabilityExposure{Kind: "pprof", Items: sortedKeys(seen)}
+	x.Total = len(x.Items)
+	return x
+}
+
+// parseExpvar never keeps flag values, only the names of secret-looking ones.
+func parseExpvar(body []byte) *ObservabilityExposure {
+	var vars map[string]json.RawMessage
+	if json.Unmarshal(body, &vars) != nil {
+		return nil
+	}
+	if _, ok := vars["memstats"]; !ok {
+		if _, ok := vars["cmdline"]; !ok {
+			return nil
+		}
+	}
+	x := &ObservabilityExposure{Kind: "expvar", Total: len(vars)}
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	x.Items = firstStrings(names, maxObservabilityItems)
+
+	var argv []string
+	if raw, ok := vars["cmdline"]; ok && json.Unmarshal(raw, &argv) == nil {
+		secrets := make(map[string]struct{})
+		for _, arg := range argv {
+			if !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			name := strings.TrimLeft(arg, "-")
+			if i := strings.IndexByte(name, '='); i >= 0 {
+				name = name[:i]
+			}
+			if name != "" && secretNameRe.MatchString(name) {
+				secrets[name] = struct{}{}
+			}
+		}
+		x.SecretNames = sortedKeys(secrets)
+	}
+	return x
+}
+
+// parseHealth reports only documents that name their dependencies; a bare {"status":"UP"} leaks nothing.
+func parseHealth(body []byte) *ObservabilityExposure {
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(body, &doc) != nil {
+		return nil
+	}
+	var status string
+	if json.Unmarshal(doc["status"], &status) != nil || !healthStatusVals[strings.ToLower(status)] {
+		return nil
+	}
+	deps := make(map[string]struct{})
+	for _, key := range []string{"components", "details", "checks", "dependencies", "services"} {
+		raw, ok := doc[key]
+		if !ok {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(raw, &obj) == nil {
+			for k := range obj {
+				deps[k] = struct{}{}
+			}
+			continue
+		}
+		var list []struct {
+			Name        string `json:"name"`
+			ComponentID string `json:"componentId"`
+		}
+		if json.Unmarshal(raw, &list) == nil {
+			for _, d := range list {
+				if d.Name != "" {
+					deps[d.Name] = struct{}{}
+				} else if d.ComponentID != "" {
+					deps[d.ComponentID] = struct{}{}
+				}
+			}
+		}
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	x := &ObservabilityExposure{Kind: "health", Items: firstStrings(sortedKeys(deps), maxObservabilityItems), Total: len(deps)}
+	return x
+}
+
+func parseJolokia(body []byte) *ObservabilityExposure {
+	var doc struct {
+		Value struct {
+			Agent string `json:"agent"`
+			Info  struct {
+				Product string `json:"product"`
+				Vendor  string `json:"vendor"`
+				Version string `json:"version"`
+			} `json:"info"`
+		} `json:"value"`
+	}
+	if json.Unmarshal(body, &doc) != nil || doc.Value.Agent == "" {
+		return nil
+	}
+	x := &ObservabilityExposure{Kind: "jolokia", Version: doc.Value.Agent}
+	if doc.Value.Info.Product != "" {
+		x.BuildInfo = map[string]string{"product": doc.Value.Info.Product, "vendor": doc.Value.Info.Vendor, "version": doc.Value.Info.Version}
+	}
+	return x
+}
+
+// parseESCat reads _cat/indices and _cat/nodes, with or without the ?v header row.
+func parseESCat(page string) *ObservabilityExposure {
+	items := make(map[string]struct{})
+	for _, m := range esCatIndexRe.FindAllStringSubmatch(page, -1) {
+		items[m[1]] = struct{}{}
+	}
+	if len(items) == 0 {
+		for _, m := range esCatNodeRe.FindAllStringSubmatch(page, -1) {
+			items[m[1]+" "+m[2]] = struct{}{}
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return &ObservabilityExposure{Kind: "elasticsearch", Items: firstStrings(sortedKeys(items), maxObservabilityItems), Total: len(items)}
+}
+
+func parseKibanaStatus(body []byte) *ObservabilityExposure {
+	var doc struct {
+		Name    string `json:"name"`
+		Version struct {
+			Number    string `json:"number"`
+			BuildHash string `json:"build_hash"`
+		} `json:"version"`
+		Status json.RawMessage `json:"status"`
+	}
+	if json.Unmarshal(body, &doc) != nil || doc.Version.Number == "" || len(doc.Status) == 0 {
+		return nil
+	}
+	x := &ObservabilityExposure{Kind: "kibana", Version: doc.Version.Number}
+	x.BuildInfo = map[string]string{"name": doc.Name, "build_hash": doc.Version.BuildHash}
+	return x
+}
+
+func parseGrafanaHealth(body []byte) *ObservabilityExposure {
+	var doc struct {
+		Commit   string `json:"commit"`
+		Database string `json:"database"`
+		Version  string `json:"version"`
+	}
+	if json.Unmarshal(body, &doc) != nil || doc.Database == "" || doc.Version == "" {
+		return nil
+	}
+	x := &ObservabilityExposure{Kind: "grafana", Version: doc.Version}
+	if doc.Commit != "" {
+		x.BuildInfo = map[string]string{"commit": doc.Commit}
+	}
+	return x
+}
+
+func applyObservabilityEvidence(x *ObservabilityExposure, a *Analysis, flags *analysisFlags) {
+	a.Interesting = true
+	flags.Observability = true
+	if sev := observabilitySeverity[x.Kind]; severityRank(a.Severity) < severityRank(sev) {
+		a.Severity = sev
+	}
+	reason := x.Kind + " endpoint exposed"
+	if x.Version != "" {
+		reason += " (version " + x.Version + ")"
+	}
+	a.Reasons = append(a.Reasons, reason)
+	if x.GoVersion != "" {
+		a.Reasons = append(a.Reasons, "discloses Go version "+x.GoVersion)
+	}
+	if len(x.SecretNames) > 0 {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "command line includes secret flags: "+joinLimited(x.SecretNames, 5))
+	}
+}
+
+func firstStrings(s []string, n int) []string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseObservability(t *testing.T) {
+	text := func(f func(string) *ObservabilityExposure) func([]byte) *ObservabilityExposure {
+		return func(b []byte) *ObservabilityExposure { return f(string(b)) }
+	}
+	tests := []struct {
+		name  string
+		parse func([]byte) *ObservabilityExposure
+		body  string
+		want  *ObservabilityExposure
+	}{
+		{
+			name:  "prometheus",
+			parse: text(parsePrometheus),
+			body: `# HELP go_goroutines Number of goroutines.
+# TYPE go_goroutines gauge
+go_goroutines 12
+# HELP go_info Information about the Go environment.
+# TYPE go_info gauge
+go_info{version="go1.22.1"} 1
+# HELP app_build_info Build information.
+# TYPE app_build_info gauge
+app_build_info{version="2.3.0",revision="abc123"} 1
+`,
+			want: &ObservabilityExposure{
+				Kind:      "prometheus",
+				Version:   "2.3.0",
+				GoVersion: "go1.22.1",
+				BuildInfo: map[string]string{"metric": "app_build_info", "version": "2.3.0", "revision": "abc123"},
+				Total:     3,
+			},
+		},
+		{name: "prometheus without families", parse: text(parsePrometheus), body: "<html>metrics</html>"},
+		{
+			name:  "pprof",
+			parse: text(parsePprofIndex),
+			body: `<html><head><title>/debug/pprof/</title></head><body>
+<a href='allocs?debug=1'>allocs</a><br>
+<a href='goroutine?debug=1'>goroutine</a><br>
+<a href='heap?debug=1'>heap</a><br>
+<a href="goroutine?debug=2">full goroutine stack dump</a>
+</body></html>`,
+			want: &ObservabilityExposure{Kind: "pprof", Items: []string{"allocs", "goroutine", "heap"}, Total: 3},
+		},
+		{
+			name:  "expvar",
+			parse: parseExpvar,
+			body:  `{"cmdline": ["/srv/app", "-listen=:8080", "--db-password=hunter2", "-api_key", "x"], "memstats": {"Alloc": 1}, "requests": 7}`,
+			want: &ObservabilityExposure{
+				Kind:        "expvar",
+				Items:       []string{"cmdline", "memstats", "requests"},
+				Total:       3,
+				SecretNames: []string{"api_key", "db-password"},
+			},
+		},
+		{name: "expvar without known vars", parse: parseExpvar, body: `{"status": "ok"}`},
+		{
+			name:  "jolokia",
+			parse: parseJolokia,
+			body:  `{"request": {"type": "version"}, "value": {"agent": "1.7.2", "protocol": "7.2", "info": {"product": "tomcat", "vendor": "Apache", "version": "9.0.80"}}, "status": 200}`,
+			want: &ObservabilityExposure{
+				Kind:      "jolokia",
+				Version:   "1.7.2",
+				BuildInfo: map[string]string{"product": "tomcat", "vendor": "Apache", "version": "9.0.80"},
+			},
+		},
+		{name: "jolokia without agent", parse: parseJolokia, body: `{"value": {}}`},
+		{
+			name:  "elasticsearch indices",
+			parse: text(parseESCat),
+			body: `health status index     uuid                   pri rep docs.count docs.deleted store.size pri.store.size
+green  open   customers 3S0KiLdQRxa7lX0bIaE8kA   1   1      12000            0      5.1mb          2.5mb
+yellow open   .kibana_1 Yb1LpZ9tT1WcDgD3TxaP6w   1   0          9            0     41.2kb         41.2kb
+`,
+			want: &ObservabilityExposure{Kind: "elasticsearch", Items: []string{".kibana_1", "customers"}, Total: 2},
+		},
+		{
+			name:  "elasticsearch nodes",
+			parse: text(parseESCat),
+			body: `10.0.0.5 41 97 3 0.15 0.10 0.08 cdfhilmrstw * es-node-1
+10.0.0.6 37 95 2 0.05 0.07 0.06 cdfhilmrstw - es-node-2
+`,
+			want: &ObservabilityExposure{Kind: "elasticsearch", Items: []string{"10.0.0.5 es-node-1", "10.0.0.6 es-node-2"}, Total: 2},
+		},
+		{name: "elasticsearch error page", parse: text(parseESCat), body: `{"error": "no handler found"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parse([]byte(tt.body)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
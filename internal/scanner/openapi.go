@@ -0,0 +1,410 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// APIInventory summarises an exposed OpenAPI/Swagger document.
+type APIInventory struct {
+	Spec            string         `json:"spec,omitempty"`
+	DocumentURL     string         `json:"document_url"`
+	Title           string         `json:"title,omitempty"`
+	Version         string         `json:"version,omitempty"`
+	Servers         []string       `json:"servers,omitempty"`
+	Operations      int            `json:"operations"`
+	Unauthenticated []APIOperation `json:"unauthenticated_operations,omitempty"`
+	Probes          []APIProbe     `json:"probes,omitempty"`
+	Error           string         `json:"error,omitempty"`
+}
+
+type APIOperation struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operation_id,omitempty"`
+}
+
+type APIProbe struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Open       bool   `json:"open"`
+	Error      string `json:"error,omitempty"`
+}
+
+const (
+	maxAPIDocBytes   = 4 << 20
+	maxAPIProbes     = 20
+	maxAPIListedOps  = 200
+	maxAPIProbeBytes = 4 << 10
+)
+
+var (
+	swaggerURLRe     = regexp.MustCompile(`(?i)\b(?:url|configUrl)\s*:\s*["']([^"']+\.(?:json|yaml|yml)|[^"']*api-docs[^"']*)["']`)
+	swaggerUIMarkers = []string{"swagger-ui", "swaggeruibundle", "swagger ui"}
+	apiDocMethods    = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+)
+
+type openAPIDoc struct {
+	OpenAPI string `json:"openapi"`
+	Swagger string `json:"swagger"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Host     string                                `json:"host"`
+	BasePath string                                `json:"basePath"`
+	Schemes  []string                              `json:"schemes"`
+	Security []map[string][]string                 `json:"security"`
+	Paths    map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+type openAPIOperation struct {
+	OperationID string                 `json:"operationId"`
+	Security    *[]map[string][]string `json:"security"`
+	Parameters  []openAPIParameter     `json:"parameters"`
+}
+
+type openAPIParameter struct {
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Ref      string `json:"$ref"`
+}
+
+// isAPIDocCandidate reports whether a response looks like an OpenAPI document or a Swagger UI page.
+func isAPIDocCandidate(snippet string) bool {
+	s := strings.TrimSpace(snippet)
+	if strings.HasPrefix(s, "{") && (strings.Contains(s, `"openapi"`) || strings.Contains(s, `"swagger"`)) {
+		return true
+	}
+	return isSwaggerUI(s)
+}
+
+func isSwaggerUI(s string) bool {
+	ls := strings.ToLower(s)
+	for _, m := range swaggerUIMarkers {
+		if strings.Contains(ls, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectAPIDocument parses the OpenAPI document behind pageURL and optionally probes it. Swagger UI
+// pages are inspected once per directory, as their markers can appear on every page.
+func inspectAPIDocument(ctx context.Context, client *http.Client, cfg Config, j job, pageURL, snippet string) *APIInventory {
+	key := "openapi:" + pageURL
+	if !strings.HasPrefix(strings.TrimSpace(snippet), "{") {
+		key = "swagger-ui:" + urlDir(pageURL)
+	}
+	if !j.state.claim(key) {
+		return nil
+	}
+	status, _, body, err := fetchRaw(ctx, client, cfg, pageURL, map[string]string{"Accept": "application/json,*/*"}, maxAPIDocBytes)
+	if err != nil || status != http.StatusOK {
+		return nil
+	}
+
+	docURL := pageURL
+	if !strings.HasPrefix(strings.TrimSpace(string(body)), "{") {
+		docURL = swaggerDocURL(ctx, client, cfg, pageURL, string(body))
+		if docURL == "" || !j.state.claim("openapi:"+docURL) {
+			return nil
+		}
+		status, _, body, err = fetchRaw(ctx, client, cfg, docURL, map[string]string{"Accept": "application/json,*/*"}, maxAPIDocBytes)
+		if err != nil {
+			return &APIInventory{DocumentURL: docURL, Error: err.Error()}
+		}
+		if status != http.StatusOK {
+			return nil
+		}
+	}
+
+	inv := &APIInventory{DocumentURL: docURL}
+	var doc openAPIDoc
+	if err := json.Unmarshal(body, &doc); err != nil {
+		inv.Error = "unsupported or invalid document: " + err.Error()
+		return inv
+	}
+	if doc.OpenAPI == "" && doc.Swagger == "" {
+		// springdoc serves a swagger-config object that points at the real document.
+		next := swaggerConfigTarget(body, docURL)
+		if next == "" || !j.state.claim("openapi:"+next) {
+			return nil
+		}
+		status, _, body, err = fetchRaw(ctx, client, cfg, next, map[string]string{"Accept": "application/json,*/*"}, maxAPIDocBytes)
+		if err != nil || status != http.StatusOK || json.Unmarshal(body, &doc) != nil || (doc.OpenAPI == "" && doc.Swagger == "") {
+			return nil
+		}
+		docURL = next
+		inv.DocumentURL = next
+	}
+	if doc.OpenAPI != "" {
+		inv.Spec = "openapi " + doc.OpenAPI
+	} else {
+		inv.Spec = "swagger " + doc.Swagger
+	}
+	inv.Title = doc.Info.Title
+	inv.Version = doc.Info.Version
+
+	base, _ := url.Parse(docURL)
+	servers := apiServerURLs(doc, base)
+	for _, s := range servers {
+		inv.Servers = append(inv.Servers, s.String())
+	}
+
+	var probes []string
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		item := doc.Paths[p]
+		var shared []openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			_ = json.Unmarshal(raw, &shared)
+		}
+		for _, m := range apiDocMethods {
+			raw, ok := item[m]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if json.Unmarshal(raw, &op) != nil {
+				continue
+			}
+			inv.Operations++
+
+			security := doc.Security
+			if op.Security != nil {
+				security = *op.Security
+			}
+			if !requiresAuth(security) {
+				if len(inv.Unauthenticated) < maxAPIListedOps {
+					inv.Unauthenticated = append(inv.Unauthenticated, APIOperation{Method: strings.ToUpper(m), Path: p, OperationID: op.OperationID})
+				}
+				if m == "get" && !strings.Contains(p, "{") && !hasRequiredParams(shared, op.Parameters) {
+					probes = append(probes, p)
+				}
+			}
+		}
+	}
+
+	if cfg.ProbeAPIs && len(servers) > 0 {
+		server := servers[0]
+		for _, p := range probes {
+			if len(inv.Probes) >= maxAPIProbes {
+				break
+			}
+			u := *server
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(p, "/")
+			pr := APIProbe{URL: u.String()}
+			st, _, _, err := fetchRaw(ctx, client, cfg, pr.URL, map[string]string{"Accept": "application/json,*/*"}, maxAPIProbeBytes)
+			if err != nil {
+				pr.Error = err.Error()
+			}
+			pr.StatusCode = st
+			pr.Open = st >= 200 && st < 300
+			inv.Probes = append(inv.Probes, pr)
+		}
+	}
+	return inv
+}
+
+// applyAPIEvidence raises documents with unauthenticated operations and probes answered without credentials.
+func applyAPIEvidence(inv *APIInventory, a *Analysis) {
+	if inv.Spec == "" {
+		return
+	}
+	a.Interesting = true
+	a.Reasons = append(a.Reasons, "API description exposed: "+strings.TrimSpace(inv.Title+" "+inv.Version))
+	if severityRank(a.Severity) < severityRank(SeverityMedium) {
+		a.Severity = SeverityMedium
+	}
+	if len(inv.Unauthenticated) > 0 {
+		a.Reasons = append(a.Reasons, "API declares operations without security requirements")
+	}
+	open := 0
+	for _, p := range inv.Probes {
+		if p.Open {
+			open++
+		}
+	}
+	if open > 0 {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "API operations respond without authentication")
+	}
+}
+
+// swaggerDocURL finds the document a Swagger UI page loads, falling back to the springdoc/springfox defaults.
+func swaggerDocURL(ctx context.Context, client *http.Client, cfg Config, pageURL, page string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	resolve := func(ref string) string {
+		r, err := url.Parse(ref)
+		if err != nil {
+			return ""
+		}
+		u := base.ResolveReference(r)
+		if !strings.EqualFold(u.Host, base.Host) {
+			return ""
+		}
+		return u.String()
+	}
+
+	// Only JSON documents are parsed, so a UI configured with a YAML document is left alone.
+	doc := func(ref string) string {
+		if ext := strings.ToLower(path.Ext(ref)); ext == ".yaml" || ext == ".yml" {
+			return ""
+		}
+		return resolve(ref)
+	}
+	if m := swaggerURLRe.FindStringSubmatch(page); m != nil {
+		return doc(m[1])
+	}
+	if st, _, js, err := fetchRaw(ctx, client, cfg, resolve("swagger-initializer.js"), nil, 64<<10); err == nil && st == http.StatusOK {
+		if m := swaggerURLRe.FindStringSubmatch(string(js)); m != nil {
+			return doc(m[1])
+		}
+	}
+	for _, p := range []string{"/v3/api-docs", "/v2/api-docs"} {
+		u := resolve(p)
+		st, _, b, err := fetchRaw(ctx, client, cfg, u, map[string]string{"Accept": "application/json"}, 4096)
+		if err == nil && st == http.StatusOK && isAPIDocCandidate(string(b)) {
+			return u
+		}
+	}
+	return ""
+}
+
+// urlDir returns the directory of a URL without its query.
+func urlDir(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	dir := u.Path
+	if dir == "" {
+		dir = "/"
+	}
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+		if dir != "/" {
+			dir += "/"
+		}
+	}
+	return u.Scheme + "://" + u.Host + dir
+}
+
+// swaggerConfigTarget returns the same-origin document referenced by a Swagger UI config object.
+func swaggerConfigTarget(body []byte, configURL string) string {
+	var sc struct {
+		URL  string `json:"url"`
+		URLs []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+	}
+	if json.Unmarshal(body, &sc) != nil {
+		return ""
+	}
+	ref := sc.URL
+	if ref == "" && len(sc.URLs) > 0 {
+		ref = sc.URLs[0].URL
+	}
+	base, err := url.Parse(configURL)
+	if ref == "" || err != nil {
+		return ""
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	u := base.ResolveReference(r)
+	if !strings.EqualFold(u.Host, base.Host) {
+		return ""
+	}
+	return u.String()
+}
+
+// apiServerURLs keeps only servers on the scanned origin so probes never leave it.
+func apiServerURLs(doc openAPIDoc, docURL *url.URL) []*url.URL {
+	if docURL == nil {
+		return nil
+	}
+	var raw []string
+	if doc.OpenAPI != "" {
+		for _, s := range doc.Servers {
+			raw = append(raw, s.URL)
+		}
+		if len(raw) == 0 {
+			raw = append(raw, "/")
+		}
+	} else {
+		bp := doc.BasePath
+		if bp == "" {
+			bp = "/"
+		}
+		if doc.Host != "" {
+			raw = append(raw, docURL.Scheme+"://"+doc.Host+bp)
+		} else {
+			raw = append(raw, bp)
+		}
+	}
+
+	var out []*url.URL
+	for _, r := range raw {
+		if strings.Contains(r, "{") {
+			// Server variables cannot be resolved without guessing.
+			continue
+		}
+		ref, err := url.Parse(r)
+		if err != nil {
+			continue
+		}
+		u := docURL.ResolveReference(ref)
+		if !strings.EqualFold(u.Host, docURL.Host) {
+			continue
+		}
+		u.RawQuery = ""
+		u.Fragment = ""
+		out = append(out, u)
+	}
+	return out
+}
+
+// requiresAuth reports whether credentials are required; an empty requirement ({}) makes them optional.
+func requiresAuth(security []map[string][]string) bool {
+	if len(security) == 0 {
+		return false
+	}
+	for _, req := range security {
+		if len(req) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func hasRequiredParams(lists ...[]openAPIParameter) bool {
+	for _, params := range lists {
+		for _, p := range params {
+			if p.Required || p.Ref != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInspectAPIDocumentSwaggerUI(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<div id="swagger-ui"></div><script>SwaggerUIBundle({url: "/openapi.json"})</script>`))
+	})
+	mux.HandleFunc("/yaml/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<div id="swagger-ui"></div><script>SwaggerUIBundle({url: "/openapi.yaml"})</script>`))
+	})
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Shop"}, "paths": {"/orders": {"get": {}}}}`))
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	cfg := Config{Timeout: 5 * time.Second}
+	j := job{state: newTargetState()}
+	snippet := `<div id="swagger-ui">`
+
+	inv := inspectAPIDocument(context.Background(), srv.Client(), cfg, j, srv.URL+"/docs/index.html", snippet)
+	if inv == nil || inv.Spec != "openapi 3.0.0" || inv.Operations != 1 {
+		t.Fatalf("inventory = %+v", inv)
+	}
+
+	// Other pages of the same directory are not fetched again.
+	before := requests.Load()
+	if inv := inspectAPIDocument(context.Background(), srv.Client(), cfg, j, srv.URL+"/docs/", snippet); inv != nil {
+		t.Errorf("second UI page in /docs/ inspected: %+v", inv)
+	}
+	if n := requests.Load() - before; n != 0 {
+		t.Errorf("second UI page cost %d requests", n)
+	}
+
+	// A YAML document is not followed.
+	before = requests.Load()
+	if inv := inspectAPIDocument(context.Background(), srv.Client(), cfg, j, srv.URL+"/yaml/", snippet); inv != nil {
+		t.Errorf("YAML document inspected: %+v", inv)
+	}
+	if n := requests.Load() - before; n != 1 {
+		t.Errorf("YAML UI page cost %d requests, want 1", n)
+	}
+}
+
+func TestURLDir(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://a.example/swagger-ui/index.html": "https://a.example/swagger-ui/",
+		"https://a.example/swagger-ui/":           "https://a.example/swagger-ui/",
+		"https://a.example/swagger-ui.html?x=1":   "https://a.example/",
+		"https://a.example":                       "https://a.example/",
+	} {
+		if got := urlDir(in); got != want {
+			t.Errorf("urlDir(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
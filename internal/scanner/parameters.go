@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Parameter is a query parameter seen in the planned URLs of a target. FileLike marks values that
+// look like file names or paths.
+type Parameter struct {
+	Name     string   `json:"name"`
+	Paths    []string `json:"paths"`
+	Values   []string `json:"sample_values,omitempty"`
+	FileLike bool     `json:"file_like,omitempty"`
+}
+
+const (
+	maxParameterPaths  = 20
+	maxParameterValues = 5
+)
+
+var fileExtRe = regexp.MustCompile(`^\.[A-Za-z0-9]{1,5}$`)
+
+// buildParameterInventory groups the query parameters of plans by name.
+func buildParameterInventory(plans []pathPlan) []Parameter {
+	byName := make(map[string]*Parameter)
+	for _, pp := range plans {
+		p, query, ok := strings.Cut(pp.Path, "?")
+		if !ok {
+			continue
+		}
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			continue
+		}
+		for name, vals := range q {
+			prm := byName[name]
+			if prm == nil {
+				prm = &Parameter{Name: name}
+				byName[name] = prm
+			}
+			if len(prm.Paths) < maxParameterPaths && !containsString(prm.Paths, p) {
+				prm.Paths = append(prm.Paths, p)
+			}
+			for _, v := range vals {
+				if isFileLikeValue(v) {
+					prm.FileLike = true
+				}
+				if v != "" && len(prm.Values) < maxParameterValues && !containsString(prm.Values, v) {
+					prm.Values = append(prm.Values, v)
+				}
+			}
+		}
+	}
+
+	out := make([]Parameter, 0, len(byName))
+	for _, prm := range byName {
+		sort.Strings(prm.Paths)
+		out = append(out, *prm)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// fileLikeParams returns the names of parameters in p whose values look like file names or paths.
+func fileLikeParams(p string) []string {
+	_, query, ok := strings.Cut(p, "?")
+	if !ok {
+		return nil
+	}
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for name, vals := range q {
+		for _, v := range vals {
+			if isFileLikeValue(v) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isFileLikeValue accepts values such as backup.sql, ../etc/passwd or C:\boot.ini, but not URLs.
+func isFileLikeValue(v string) bool {
+	v = strings.TrimSpace(v)
+	if v == "" || len(v) > 256 || strings.Contains(v, "://") || strings.ContainsAny(v, " <>\"") {
+		return false
+	}
+	if strings.Contains(v, "/") || strings.Contains(v, "\\") {
+		return true
+	}
+	ext := path.Ext(v)
+	if !fileExtRe.MatchString(ext) || len(ext) == len(v) {
+		return false
+	}
+	// Numbers such as 1.5 are not file names.
+	return strings.ContainsAny(ext[1:], "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestIsFileLikeValue(t *testing.T) {
+	tests := []struct {
+		v    string
+		want bool
+	}{
+		{"backup.sql", true},
+		{"report.PDF", true},
+		{"../etc/passwd", true},
+		{"/var/www/html/index.php", true},
+		{`C:\boot.ini`, true},
+		{"1.5", false},
+		{"2024.01", false},
+		{"v1.2.3", false},
+		{".htaccess", false},
+		{"admin", false},
+		{"", false},
+		{"https://example.com/a.js", false},
+		{"a file.txt", false},
+		{"name.toolongext", false},
+	}
+	for _, tt := range tests {
+		if got := isFileLikeValue(tt.v); got != tt.want {
+			t.Errorf("isFileLikeValue(%q) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestBuildParameterInventory(t *testing.T) {
+	plans := []pathPlan{
+		{Path: "/download?file=report.pdf"},
+		{Path: "/download?file=..%2F..%2Fetc%2Fpasswd"},
+		{Path: "/item?id=1"},
+		{Path: "/item?id=2&ver=1.5"},
+		{Path: "/search?id=1"},
+		{Path: "/about"},
+	}
+	want := []Parameter{
+		{Name: "file", Paths: []string{"/download"}, Values: []string{"report.pdf", "../../etc/passwd"}, FileLike: true},
+		{Name: "id", Paths: []string{"/item", "/search"}, Values: []string{"1", "2"}},
+		{Name: "ver", Paths: []string{"/item"}, Values: []string{"1.5"}},
+	}
+	if got := buildParameterInventory(plans); !reflect.DeepEqual(got, want) {
+		t.Errorf("inventory = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestMeaningfulQuery(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"b=2&a=1", "a=1&b=2"},
+		{"utm_source=x&UTM_Medium=y&id=3", "id=3"},
+		{"gclid=abc&fbclid=def&_ga=1", ""},
+		{"file=a%20b.txt", "file=a+b.txt"},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.in)
+		if got := meaningfulQuery(q); got != tt.want {
+			t.Errorf("meaningfulQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlanKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"/item?id=1", "/item?id=2", true},
+		{"/item?id=1&sort=asc", "/item?sort=desc&id=9", true},
+		{"/item?id=1", "/item?file=x", false},
+		{"/item", "/item?id=1", false},
+		{"/item?", "/item", true},
+	}
+	for _, tt := range tests {
+		if got := planKey(tt.a) == planKey(tt.b); got != tt.same {
+			t.Errorf("planKey(%q) == planKey(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestNormalizeURLToSameOriginPath(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"https://example.com/a/b?utm_source=news&id=7", "/a/b?id=7", true},
+		{"/download?file=x.zip#part", "/download?file=x.zip", true},
+		{"/a/../b//c", "/b/c", true},
+		{"https://other.example/a", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeURLToSameOriginPath(base, tt.raw)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeURLToSameOriginPath(%q) = %q, %v, want %q, %v", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
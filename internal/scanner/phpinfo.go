@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// PHPInfo holds the security-relevant facts extracted from a phpinfo() page.
+type PHPInfo struct {
+	Version          string   `json:"version,omitempty"`
+	LoadedConfigFile string   `json:"loaded_config_file,omitempty"`
+	DocumentRoot     string   `json:"document_root,omitempty"`
+	DisableFunctions string   `json:"disable_functions,omitempty"`
+	AllowURLInclude  string   `json:"allow_url_include,omitempty"`
+	DisplayErrors    string   `json:"display_errors,omitempty"`
+	SecretEnvVars    []string `json:"secret_env_vars,omitempty"`
+
+	directives          int
+	hasDisableFunctions bool
+}
+
+const maxPHPInfoBytes = 2 << 20
+
+var (
+	phpinfoVersionRe = regexp.MustCompile(`(?i)PHP Version\s*(?:</[^>]+>\s*<[^>]+>\s*)?(?:=>\s*)?([0-9][0-9A-Za-z.\-+~]*)`)
+	phpinfoRowRe     = regexp.MustCompile(`(?is)<tr>\s*<td class="e">(.*?)</td>\s*<td class="v">(.*?)</td>`)
+	phpinfoTextRe    = regexp.MustCompile(`(?m)^([^\n=]+?) => ([^\n]*?)(?: => [^\n]*)?$`)
+	phpinfoTagRe     = regexp.MustCompile(`(?s)<[^>]*>`)
+	phpinfoEnvKeyRe  = regexp.MustCompile(`^\$_(?:SERVER|ENV)\['([^']+)'\]$`)
+	// php.ini directives are lower-case identifiers, optionally dotted: memory_limit, session.save_path.
+	phpinfoDirectiveRe = regexp.MustCompile(`^[a-z][a-z0-9_]*(?:\.[a-z0-9_]+)*$`)
+)
+
+// isPHPInfo is a cheap pre-check on the snippet; parsePHPInfo decides whether the page is real.
+func isPHPInfo(snippet string) bool {
+	return strings.Contains(snippet, "phpinfo()") || strings.Contains(snippet, "PHP Version")
+}
+
+// parsePHPInfo reads the HTML and CLI renderings; environment rows are kept by name only.
+func parsePHPInfo(page string) *PHPInfo {
+	info := &PHPInfo{}
+	if m := phpinfoVersionRe.FindStringSubmatch(page); m != nil {
+		info.Version = m[1]
+	}
+
+	type kv struct{ k, v string }
+	var rows []kv
+	for _, m := range phpinfoRowRe.FindAllStringSubmatch(page, -1) {
+		k := strings.TrimSpace(html.UnescapeString(phpinfoTagRe.ReplaceAllString(m[1], "")))
+		v := strings.TrimSpace(html.UnescapeString(phpinfoTagRe.ReplaceAllString(m[2], "")))
+		rows = append(rows, kv{k, v})
+	}
+	if len(rows) == 0 {
+		for _, m := range phpinfoTextRe.FindAllStringSubmatch(page, -1) {
+			rows = append(rows, kv{strings.TrimSpace(m[1]), strings.TrimSpace(m[2])})
+		}
+	}
+
+	secrets := make(map[string]struct{})
+	for _, r := range rows {
+		switch r.k {
+		case "Loaded Configuration File":
+			info.LoadedConfigFile = r.v
+		case "disable_functions":
+			info.DisableFunctions = r.v
+			info.hasDisableFunctions = true
+		case "allow_url_include":
+			info.AllowURLInclude = r.v
+		case "display_errors":
+			info.DisplayErrors = r.v
+		case "DOCUMENT_ROOT", "$_SERVER['DOCUMENT_ROOT']":
+			info.DocumentRoot = r.v
+		}
+
+		if phpinfoDirectiveRe.MatchString(r.k) {
+			info.directives++
+		}
+
+		name := r.k
+		if m := phpinfoEnvKeyRe.FindStringSubmatch(name); m != nil {
+			name = m[1]
+		}
+		if r.v != "" && r.v != "no value" && isEnvVarName(name) && secretNameRe.MatchString(name) {
+			secrets[name] = struct{}{}
+		}
+	}
+	info.SecretEnvVars = sortedKeys(secrets)
+	return info
+}
+
+// valid requires a PHP version and at least one directive row.
+func (info *PHPInfo) valid() bool {
+	return info.Version != "" && info.directives > 0
+}
+
+// isEnvVarName accepts upper-case identifiers such as DB_PASSWORD, not php.ini directives.
+func isEnvVarName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func phpFlagOn(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "on", "1", "true", "yes", "stderr", "stdout":
+		return true
+	}
+	return false
+}
+
+func applyPHPInfoEvidence(info *PHPInfo, a *Analysis) {
+	a.Interesting = true
+	if info.Version != "" {
+		a.Reasons = append(a.Reasons, "phpinfo discloses PHP "+info.Version)
+	}
+	if severityRank(a.Severity) < severityRank(SeverityMedium) {
+		a.Severity = SeverityMedium
+	}
+	if len(info.SecretEnvVars) > 0 {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "phpinfo exposes secret environment variables: "+joinLimited(info.SecretEnvVars, 5))
+	}
+	if phpFlagOn(info.AllowURLInclude) {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "allow_url_include is enabled")
+	}
+	if phpFlagOn(info.DisplayErrors) {
+		a.Reasons = append(a.Reasons, "display_errors is enabled")
+	}
+	if d := strings.TrimSpace(info.DisableFunctions); info.hasDisableFunctions && (d == "" || d == "no value") {
+		a.Reasons = append(a.Reasons, "disable_functions is empty")
+	}
+}
@@ -0,0 +1,45 @@
+package scanner
+
+import "testing"
+
+func TestParsePHPInfo(t *testing.T) {
+	tests := []struct {
+		name          string
+		page          string
+		valid         bool
+		emptyDisabled bool
+	}{
+		{
+			name: "html",
+			page: `<h1 class="p">PHP Version 8.2.1</h1><table>` +
+				`<tr><td class="e">memory_limit</td><td class="v">128M</td><td class="v">128M</td></tr>` +
+				`<tr><td class="e">disable_functions</td><td class="v"><i>no value</i></td><td class="v"><i>no value</i></td></tr>` +
+				`</table>`,
+			valid:         true,
+			emptyDisabled: true,
+		},
+		{
+			name:  "cli text without disable_functions",
+			page:  "phpinfo()\nPHP Version => 7.4.3\n\nmemory_limit => -1 => -1\n",
+			valid: true,
+		},
+		{name: "tutorial mentioning phpinfo()", page: "<p>Call phpinfo() to see the PHP Version 8 settings.</p>"},
+		{name: "version without directives", page: "PHP Version 8.1.0\nServer => nginx\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := parsePHPInfo(tt.page)
+			if info.valid() != tt.valid {
+				t.Fatalf("valid() = %v, want %v (%+v)", info.valid(), tt.valid, info)
+			}
+			if !tt.valid {
+				return
+			}
+			var a Analysis
+			applyPHPInfoEvidence(info, &a)
+			if got := containsString(a.Reasons, "disable_functions is empty"); got != tt.emptyDisabled {
+				t.Errorf("disable_functions reason = %v, want %v (%q)", got, tt.emptyDisabled, a.Reasons)
+			}
+		})
+	}
+}
@@ -20,17 +20,33 @@ func recommendedFix(path string, source DiscoverySource, a Analysis, flags analy
 	if strings.HasPrefix(lp, "/.env") {
 		return "Remove environment files from the web root and restrict access; rotate any exposed credentials."
 	}
-	ext := strings.ToLower(filepath.Ext(lp))
+	p, _, _ := strings.Cut(lp, "?")
+	ext := filepath.Ext(p)
 	switch ext {
 	case ".zip", ".tar", ".gz", ".sql":
 		return "Remove backup/dump artifacts from public paths and restrict access to internal storage."
 	}
-	if strings.Contains(lp, "phpinfo") {
+	if strings.Contains(p, "phpinfo") {
 		return "Remove phpinfo endpoints from production or restrict access to administrators only."
 	}
-	if strings.Contains(lp, "actuator") {
+	if strings.Contains(p, "actuator") {
 		return "Restrict Spring Boot actuator endpoints to authenticated/internal access and disable sensitive endpoints."
 	}
+	if flags.SourceMap {
+		return "Stop publishing source maps to production (or restrict them to internal users) and review the bundled sources for secrets."
+	}
+	if flags.GraphQLIntrospection {
+		return "Disable GraphQL introspection and IDEs in production and require authentication for the GraphQL endpoint."
+	}
+	if flags.Observability {
+		return "Serve monitoring and debug endpoints on an internal listener or behind authentication; do not expose them publicly."
+	}
+	if flags.RobotsLeak {
+		return "Remove sensitive paths from robots.txt, which is public and advertises them, and protect those paths with authentication instead."
+	}
+	if flags.FileParameter {
+		return "Resolve file parameters against an allowlist or server-side IDs and reject path separators and traversal sequences."
+	}
 
 	if source == SourceSitemap {
 		return "If this content should not be indexed, remove it from the sitemap and restrict access."
@@ -0,0 +1,214 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Jason-0902/wdf/internal/discover"
+)
+
+// RobotsLeak lists robots.txt rules that advertise sensitive areas, with what the scan observed.
+type RobotsLeak struct {
+	Entries   []RobotsLeakEntry `json:"entries"`
+	Reachable int               `json:"reachable"`
+}
+
+// RobotsLeakEntry is one leaking rule; Score sums its keyword weights and sensitive path matches.
+type RobotsLeakEntry struct {
+	Directive string            `json:"directive"`
+	Pattern   string            `json:"pattern"`
+	Agents    []string          `json:"user_agents"`
+	Score     int               `json:"score"`
+	Keywords  []string          `json:"keywords,omitempty"`
+	Category  string            `json:"category,omitempty"`
+	Paths     []RobotsPathCheck `json:"paths,omitempty"`
+	Reachable bool              `json:"reachable"`
+}
+
+// RobotsPathCheck is a path derived from a rule; StatusCode is zero when it was not requested.
+type RobotsPathCheck struct {
+	Path       string `json:"path"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Reachable  bool   `json:"reachable"`
+}
+
+const minRobotsLeakScore = 3
+
+// robotsLeakKeywords weighs words found in robots.txt paths.
+var robotsLeakKeywords = map[string]int{
+	"admin": 3, "administrator": 3, "backup": 4, "backups": 4, "bak": 3, "old": 2, "internal": 3,
+	"private": 3, "secret": 4, "secrets": 4, "config": 3, "conf": 2, "debug": 3, "staging": 2,
+	"dev": 2, "test": 1, "db": 3, "database": 3, "sql": 3, "dump": 4, "log": 2, "logs": 2,
+	"hidden": 3, "install": 2, "setup": 2, "phpmyadmin": 4, "console": 3, "dashboard": 2,
+	"manage": 2, "management": 2, "credentials": 5, "keys": 3, "token": 3, "export": 2,
+	"tmp": 1, "temp": 1, "api": 1, "cgi-bin": 1, "wp-admin": 2,
+}
+
+// analyzeRobotsLeak returns the leaking rules; statuses maps planKey(path) to the observed status.
+func analyzeRobotsLeak(robots *discover.RobotsFile, rs RuleSet, statuses map[string]int) *RobotsLeak {
+	if robots == nil {
+		return nil
+	}
+	byRule := make(map[string]*RobotsLeakEntry)
+	var order []string
+	add := func(directive, pattern string, agents []string) {
+		key := directive + ":" + pattern
+		if e, ok := byRule[key]; ok {
+			e.Agents = dedupeStrings(append(e.Agents, agents...))
+			return
+		}
+		e := scoreRobotsRule(pattern, rs)
+		if e.Score < minRobotsLeakScore {
+			return
+		}
+		e.Directive, e.Pattern, e.Agents = directive, pattern, append([]string(nil), agents...)
+		for _, p := range discover.ExpandRobotsPattern(pattern) {
+			n, ok := normalizePath(p)
+			if !ok {
+				continue
+			}
+			st := statuses[planKey(n)]
+			c := RobotsPathCheck{Path: n, StatusCode: st, Reachable: st >= 200 && st < 300}
+			e.Reachable = e.Reachable || c.Reachable
+			e.Paths = append(e.Paths, c)
+		}
+		byRule[key] = e
+		order = append(order, key)
+	}
+	for _, g := range robots.Groups {
+		for _, d := range g.Disallow {
+			add("disallow", d, g.Agents)
+		}
+		for _, a := range g.Allow {
+			add("allow", a, g.Agents)
+		}
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	leak := &RobotsLeak{}
+	for _, k := range order {
+		e := byRule[k]
+		if e.Reachable {
+			leak.Reachable++
+		}
+		leak.Entries = append(leak.Entries, *e)
+	}
+	sort.SliceStable(leak.Entries, func(i, j int) bool { return leak.Entries[i].Score > leak.Entries[j].Score })
+	return leak
+}
+
+func scoreRobotsRule(pattern string, rs RuleSet) *RobotsLeakEntry {
+	e := &RobotsLeakEntry{}
+	lp := strings.ToLower(strings.TrimSuffix(pattern, "$"))
+	if lp == "" || lp == "/" || lp == "/*" {
+		return e
+	}
+
+	seen := make(map[string]bool)
+	for _, seg := range strings.Split(lp, "/") {
+		if strings.HasPrefix(seg, ".") && len(seg) > 1 && !seen[seg] {
+			// Dot files and directories such as /.git/ or /.env are never meant to be public.
+			seen[seg] = true
+			e.Score += 3
+			e.Keywords = append(e.Keywords, seg)
+		}
+		words := strings.FieldsFunc(seg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		})
+		// Hyphenated names like cgi-bin and wp-admin are weighed as a whole.
+		words = append(words, seg)
+		for _, w := range words {
+			if wt, ok := robotsLeakKeywords[w]; ok && !seen[w] {
+				seen[w] = true
+				e.Score += wt
+				e.Keywords = append(e.Keywords, w)
+			}
+		}
+	}
+
+	// A wildcard inside a segment leaves only the whole segments before it.
+	prefix := lp
+	if i := strings.IndexByte(prefix, '*'); i >= 0 {
+		prefix = prefix[:strings.LastIndexByte(prefix[:i], '/')+1]
+	}
+	if len(prefix) > 1 {
+		for _, r := range rs.SensitivePathRules {
+			if !robotsRuleCovers(prefix, strings.ToLower(r.Path)) {
+				continue
+			}
+			e.Category = r.Category
+			if e.Category == "" {
+				e.Category = "sensitive path"
+			}
+			if r.Critical {
+				e.Score += 5
+			} else {
+				e.Score += 3
+			}
+			break
+		}
+	}
+	return e
+}
+
+// robotsRuleCovers compares whole segments: /admin covers /admin/config.php but not /administrator.
+func robotsRuleCovers(prefix, rp string) bool {
+	dir := strings.TrimSuffix(prefix, "/")
+	if dir == strings.TrimSuffix(rp, "/") {
+		return true
+	}
+	return strings.HasPrefix(rp, dir+"/")
+}
+
+// inspectRobotsLeak records the finding on the target and on its /robots.txt result.
+func inspectRobotsLeak(tr *TargetResult, rs RuleSet) {
+	if tr.Robots == nil {
+		return
+	}
+	statuses := make(map[string]int, len(tr.Results))
+	for _, r := range tr.Results {
+		if r.StatusCode != 0 {
+			statuses[planKey(r.Path)] = r.StatusCode
+		}
+	}
+	leak := analyzeRobotsLeak(tr.Robots, rs, statuses)
+	if leak == nil {
+		return
+	}
+	tr.RobotsLeak = leak
+
+	for i := range tr.Results {
+		rr := &tr.Results[i]
+		if rr.Path != "/robots.txt" || rr.StatusCode < 200 || rr.StatusCode >= 300 {
+			continue
+		}
+		rr.evidence().Robots = leak
+		applyRobotsLeakEvidence(leak, &rr.Analysis, &rr.flags)
+		rr.Analysis.Reasons = dedupeStrings(rr.Analysis.Reasons)
+		rr.RecommendedFix = recommendedFix(rr.Path, rr.DiscoverySource, rr.Analysis, rr.flags, rr.sensitive)
+	}
+}
+
+func applyRobotsLeakEvidence(leak *RobotsLeak, a *Analysis, flags *analysisFlags) {
+	flags.RobotsLeak = true
+	a.Interesting = true
+	patterns := make([]string, 0, len(leak.Entries))
+	var reachable []string
+	for _, e := range leak.Entries {
+		patterns = append(patterns, e.Pattern)
+		for _, c := range e.Paths {
+			if c.Reachable {
+				reachable = append(reachable, c.Path)
+			}
+		}
+	}
+	a.Reasons = append(a.Reasons, "robots.txt advertises sensitive paths: "+joinLimited(patterns, 5))
+	if len(reachable) > 0 {
+		if severityRank(a.Severity) < severityRank(SeverityMedium) {
+			a.Severity = SeverityMedium
+		}
+		a.Reasons = append(a.Reasons, "paths listed in robots.txt are reachable: "+joinLimited(reachable, 5))
+	}
+}
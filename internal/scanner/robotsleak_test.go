@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/Jason-0902/wdf/internal/discover"
+)
+
+func TestScoreRobotsRuleSensitivePath(t *testing.T) {
+	rs := RuleSet{SensitivePathRules: []SensitivePathRule{{Path: "/admin/config.php", Category: "config", Critical: true}}}
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"/admin/config.php", true},
+		{"/admin/", true},
+		{"/admin", true},
+		{"/admin/*", true},
+		{"/adm", false},
+		{"/adm*", false},
+		{"/administrator/", false},
+		{"/admin/config.php.bak", false},
+		{"/admin/config.php/extra", false},
+	}
+	for _, tt := range tests {
+		if got := scoreRobotsRule(tt.pattern, rs).Category != ""; got != tt.want {
+			t.Errorf("scoreRobotsRule(%q) matched = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestInspectRobotsLeak(t *testing.T) {
+	tr := &TargetResult{
+		Normalized: "https://example.com",
+		Robots:     &discover.RobotsFile{Groups: []discover.RobotsGroup{{Agents: []string{"*"}, Disallow: []string{"/backup/"}}}},
+		Results:    []RequestResult{{Path: "/backup", StatusCode: 200}},
+	}
+	inspectRobotsLeak(tr, RuleSet{})
+	if tr.RobotsLeak == nil || tr.RobotsLeak.Reachable != 1 {
+		t.Fatalf("RobotsLeak = %+v, want one reachable entry", tr.RobotsLeak)
+	}
+	if len(tr.Results) != 1 {
+		t.Errorf("inspectRobotsLeak added results that were never requested: %+v", tr.Results)
+	}
+
+	tr.Results = append(tr.Results, RequestResult{Path: "/robots.txt", StatusCode: 200, Analysis: Analysis{Severity: SeverityLow}})
+	inspectRobotsLeak(tr, RuleSet{})
+	if rr := tr.Results[1]; rr.Evidence == nil || rr.Evidence.Robots == nil || rr.Analysis.Severity != SeverityMedium {
+		t.Errorf("scanned /robots.txt not annotated: %+v", rr)
+	}
+
+	// The fix is recomputed from what the scan already found, not from the leak alone.
+	secret := RequestResult{Path: "/robots.txt", StatusCode: 200, Analysis: Analysis{Severity: SeverityHigh}, flags: analysisFlags{ConfirmedSecret: true}}
+	tr.Results = []RequestResult{secret}
+	inspectRobotsLeak(tr, RuleSet{})
+	if rr := tr.Results[0]; !rr.flags.RobotsLeak || rr.RecommendedFix != recommendedFix(rr.Path, rr.DiscoverySource, rr.Analysis, analysisFlags{ConfirmedSecret: true}, false) {
+		t.Errorf("fix = %q, want the confirmed secret fix", rr.RecommendedFix)
+	}
+}
@@ -19,6 +19,19 @@ type Pattern struct {
 type SensitivePathRule struct {
 	Path     string
 	Critical bool
+	// Rules in a content-validated category need an inspector to recognise the response.
+	Category string
+}
+
+const (
+	// CategoryObservability covers monitoring and debug endpoints (metrics, profilers, health checks).
+	CategoryObservability = "observability"
+	// CategoryGraphQL covers GraphQL endpoints and IDEs, confirmed with a {__typename} query.
+	CategoryGraphQL = "graphql"
+)
+
+func contentValidated(category string) bool {
+	return category == CategoryObservability || category == CategoryGraphQL
 }
 
 type RuleSet struct {
@@ -52,11 +65,28 @@ var defaultSensitivePathRules = []SensitivePathRule{
 	{Path: "/swagger/index.html", Critical: false},
 	{Path: "/swagger-ui.html", Critical: false},
 	{Path: "/openapi.json", Critical: false},
+	{Path: "/actuator", Critical: false},
 	{Path: "/actuator/env", Critical: true},
 	{Path: "/actuator/configprops", Critical: false},
 	{Path: "/actuator/heapdump", Critical: true},
 	{Path: "/actuator/beans", Critical: false},
+	{Path: "/env", Critical: false},
 	{Path: "/server-status", Critical: false},
+	{Path: "/metrics", Critical: false, Category: CategoryObservability},
+	{Path: "/debug/pprof/", Critical: false, Category: CategoryObservability},
+	{Path: "/debug/vars", Critical: false, Category: CategoryObservability},
+	{Path: "/healthz", Critical: false, Category: CategoryObservability},
+	{Path: "/health", Critical: false, Category: CategoryObservability},
+	{Path: "/jolokia", Critical: false, Category: CategoryObservability},
+	{Path: "/_cat/indices", Critical: false, Category: CategoryObservability},
+	{Path: "/_cat/nodes", Critical: false, Category: CategoryObservability},
+	{Path: "/api/status", Critical: false, Category: CategoryObservability},
+	{Path: "/api/health", Critical: false, Category: CategoryObservability},
+	{Path: "/graphql", Critical: false, Category: CategoryGraphQL},
+	{Path: "/api/graphql", Critical: false, Category: CategoryGraphQL},
+	{Path: "/v1/graphql", Critical: false, Category: CategoryGraphQL},
+	{Path: "/graphiql", Critical: false, Category: CategoryGraphQL},
+	{Path: "/playground", Critical: false, Category: CategoryGraphQL},
 	{Path: "/.DS_Store", Critical: false},
 	{Path: "/.well-known/security.txt", Critical: false},
 	{Path: "/sitemap.xml", Critical: false},
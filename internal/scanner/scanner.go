@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/url"
 	"sort"
@@ -22,9 +23,20 @@ type RequestResult struct {
 	Error           string              `json:"error,omitempty"`
 	DurationMs      int64               `json:"duration_ms"`
 	IndexedExposed  bool                `json:"indexed_exposed"`
+	IndexCheckError *IndexCheckError    `json:"index_check_error,omitempty"`
 	DiscoverySource DiscoverySource     `json:"discovery_source,omitempty"`
-	RecommendedFix  string              `json:"recommended_fix,omitempty"`
-	Analysis        Analysis            `json:"analysis"`
+	// DiscoverySources lists every source of the path; Referrer is the file that first referenced it.
+	DiscoverySources []DiscoverySource `json:"discovery_sources,omitempty"`
+	Referrer         string            `json:"referrer,omitempty"`
+	SitemapLastMod   string            `json:"sitemap_lastmod,omitempty"`
+	Indexability     *Indexability     `json:"indexability,omitempty"`
+	RecommendedFix   string            `json:"recommended_fix,omitempty"`
+	Analysis         Analysis          `json:"analysis"`
+	Evidence         *Evidence         `json:"evidence,omitempty"`
+
+	// flags and sensitive let findings added after the scan recompute RecommendedFix.
+	flags     analysisFlags
+	sensitive bool
 }
 
 type Analysis struct {
@@ -40,6 +52,14 @@ type TargetResult struct {
 	StartedAt  time.Time       `json:"started_at"`
 	FinishedAt time.Time       `json:"finished_at"`
 	Results    []RequestResult `json:"results"`
+
+	Crawl      *discover.CrawlStats `json:"crawl,omitempty"`
+	Robots     *discover.RobotsFile `json:"robots,omitempty"`
+	Sitemap    *SitemapReport       `json:"sitemap,omitempty"`
+	RobotsLeak *RobotsLeak          `json:"robots_leak,omitempty"`
+	GraphQL    []GraphQLExposure    `json:"graphql,omitempty"`
+	Discovery  *DiscoveryReport     `json:"discovery,omitempty"`
+	Parameters []Parameter          `json:"parameters,omitempty"`
 }
 
 type DiscoverySource string
@@ -49,8 +69,24 @@ const (
 	SourceRobots     DiscoverySource = "robots"
 	SourceSitemap    DiscoverySource = "sitemap"
 	SourceCrawler    DiscoverySource = "crawler"
+	SourceDSStore    DiscoverySource = "ds_store"
+	SourceListing    DiscoverySource = "directory_listing"
+	SourceErrorProbe DiscoverySource = "error_probe"
+	SourceJS         DiscoverySource = "js"
 )
 
+// DiscoveryReport records how discovery went; a path found by several sources counts for each.
+type DiscoveryReport struct {
+	RobotsStatus    int                     `json:"robots_status,omitempty"`
+	SitemapsFetched int                     `json:"sitemaps_fetched"`
+	SitemapsFailed  int                     `json:"sitemaps_failed"`
+	PagesCrawled    int                     `json:"pages_crawled"`
+	JSPaths         int                     `json:"js_paths,omitempty"`
+	JSPathsDropped  int                     `json:"js_paths_dropped,omitempty"`
+	PathsBySource   map[DiscoverySource]int `json:"paths_by_source"`
+	Errors          []string                `json:"errors,omitempty"`
+}
+
 type job struct {
 	target     string
 	baseURL    *url.URL
@@ -58,6 +94,68 @@ type job struct {
 	isSensitive bool
 	critical    bool
 	source      DiscoverySource
+	depth       int
+	lastMod     string
+	sources     []DiscoverySource
+	referrer    string
+	state       *targetState
+}
+
+// targetState is shared by every job of one target so follow-up inspections can coordinate.
+type targetState struct {
+	mu       sync.Mutex
+	claimed  map[string]struct{}
+	planned  map[string]struct{}
+	followUp int
+
+	maxFollowUps int
+	maxDepth     int
+	submit       func(pathPlan)
+	// robots is the target's robots.txt, set before any job is queued.
+	robots *discover.RobotsFile
+}
+
+func newTargetState() *targetState {
+	return &targetState{claimed: make(map[string]struct{}), planned: make(map[string]struct{})}
+}
+
+// claim reports whether key was not yet claimed for this target, claiming it if so.
+func (ts *targetState) claim(key string) bool {
+	if ts == nil {
+		return true
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if _, ok := ts.claimed[key]; ok {
+		return false
+	}
+	ts.claimed[key] = struct{}{}
+	return true
+}
+
+// enqueue schedules a discovered path unless it is already planned or over the follow-up limits.
+func (ts *targetState) enqueue(pp pathPlan) bool {
+	if ts == nil || ts.submit == nil {
+		return false
+	}
+	n, ok := normalizePath(pp.Path)
+	if !ok || len(n) > 2048 || pp.Depth > ts.maxDepth {
+		return false
+	}
+	pp.Path = n
+	key := planKey(n)
+
+	ts.mu.Lock()
+	if _, exists := ts.planned[key]; exists || ts.followUp >= ts.maxFollowUps {
+		ts.mu.Unlock()
+		return false
+	}
+	ts.planned[key] = struct{}{}
+	ts.followUp++
+	ts.mu.Unlock()
+
+	ts.submit(pp)
+	return true
 }
 
 type jobResult struct {
@@ -112,17 +210,22 @@ func ScanTargets(ctx context.Context, targets []string, cfg Config, rs RuleSet)
 		out = append(out, tr)
 	}
 
+	// mu guards out, which the producer updates with discovery stats while results arrive.
+	var mu sync.Mutex
 	jobs := make(chan job)
 	results := make(chan jobResult, cfg.Concurrency*2)
 
 	client := newHTTPClient(cfg)
 
+	// pending counts unfinished jobs, follow-ups included, so jobs is closed only when none can be added.
+	var pending sync.WaitGroup
 	var wg sync.WaitGroup
 	worker := func() {
 		defer wg.Done()
 		for j := range jobs {
-			rr := scanOne(ctx, client, cfg, rs, j.baseURL, j.path, j.isSensitive, j.critical, j.source)
+			rr := scanOne(ctx, client, cfg, rs, j)
 			results <- jobResult{target: j.target, rr: rr}
+			pending.Done()
 		}
 	}
 
@@ -132,38 +235,68 @@ func ScanTargets(ctx context.Context, targets []string, cfg Config, rs RuleSet)
 	}
 
 	go func() {
-		// Close jobs first so workers can exit; then close results after all workers finish.
+		defer func() {
+			pending.Wait()
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+
 		for _, ti := range infos {
 			if ti.err != "" {
 				continue
 			}
 
-			pathPlans := buildPathPlan(ctx, client, cfg, rs, ti.u)
+			state := newTargetState()
+			state.maxFollowUps = cfg.FollowUpLimit
+			if state.maxFollowUps <= 0 {
+				state.maxFollowUps = 200
+			}
+			state.maxDepth = cfg.FollowUpDepth
+			if state.maxDepth <= 0 {
+				state.maxDepth = 3
+			}
+			state.submit = func(pp pathPlan) {
+				// Called from workers, so hand off to a goroutine instead of blocking on jobs.
+				pending.Add(1)
+				go func() {
+					select {
+					case <-ctx.Done():
+						pending.Done()
+					case jobs <- planJob(ti.raw, ti.u, pp, state):
+					}
+				}()
+			}
+
+			pathPlans, disc := buildPathPlan(ctx, client, cfg, rs, ti.u)
+			params := buildParameterInventory(pathPlans)
+			mu.Lock()
+			out[idxByTarget[ti.raw]].Crawl = disc.crawl
+			out[idxByTarget[ti.raw]].Robots = disc.robots
+			out[idxByTarget[ti.raw]].Sitemap = disc.sitemap
+			out[idxByTarget[ti.raw]].Discovery = &disc.telemetry
+			out[idxByTarget[ti.raw]].Parameters = params
+			mu.Unlock()
+			state.robots = disc.robots
+			state.mu.Lock()
+			for _, pp := range pathPlans {
+				state.planned[planKey(pp.Path)] = struct{}{}
+			}
+			state.mu.Unlock()
+
 			for _, pp := range pathPlans {
+				pending.Add(1)
 				select {
 				case <-ctx.Done():
-					close(jobs)
-					wg.Wait()
-					close(results)
+					pending.Done()
 					return
-				case jobs <- job{
-					target:      ti.raw,
-					baseURL:     ti.u,
-					path:        pp.Path,
-					isSensitive: pp.IsSensitive,
-					critical:    pp.Critical,
-					source:      pp.Source,
-				}:
+				case jobs <- planJob(ti.raw, ti.u, pp, state):
 				}
 			}
 		}
-		close(jobs)
-		wg.Wait()
-		close(results)
 	}()
 
 	// Aggregate.
-	var mu sync.Mutex
 	for jr := range results {
 		mu.Lock()
 		i := idxByTarget[jr.target]
@@ -176,6 +309,19 @@ func ScanTargets(ctx context.Context, targets []string, cfg Config, rs RuleSet)
 		if out[i].FinishedAt.IsZero() {
 			out[i].FinishedAt = time.Now().UTC()
 		}
+		// Reachability of robots.txt entries is only known once every path has been scanned.
+		inspectRobotsLeak(&out[i], rs)
+		inspectSitemapFlags(&out[i])
+		applyIndexChecks(ctx, cfg.IndexChecker, &out[i])
+		collectGraphQL(&out[i])
+		if d := out[i].Discovery; d != nil {
+			d.PathsBySource = make(map[DiscoverySource]int)
+			for _, r := range out[i].Results {
+				for _, s := range r.DiscoverySources {
+					d.PathsBySource[s]++
+				}
+			}
+		}
 		sort.Slice(out[i].Results, func(a, b int) bool {
 			if out[i].Results[a].Path == out[i].Results[b].Path {
 				return out[i].Results[a].URL < out[i].Results[b].URL
@@ -192,36 +338,102 @@ type pathPlan struct {
 	IsSensitive bool
 	Critical    bool
 	Source      DiscoverySource
+	Depth       int
+	LastMod     string // sitemap <lastmod>, when a sitemap listed the URL
+	// Sources lists every source of the path; Referrer is the file that first referenced it.
+	Sources  []DiscoverySource
+	Referrer string
+}
+
+func planJob(target string, base *url.URL, pp pathPlan, state *targetState) job {
+	sources := pp.Sources
+	if len(sources) == 0 && pp.Source != "" {
+		sources = []DiscoverySource{pp.Source}
+	}
+	return job{
+		target:      target,
+		baseURL:     base,
+		path:        pp.Path,
+		isSensitive: pp.IsSensitive,
+		critical:    pp.Critical,
+		source:      pp.Source,
+		depth:       pp.Depth,
+		lastMod:     pp.LastMod,
+		sources:     sources,
+		referrer:    pp.Referrer,
+		state:       state,
+	}
 }
 
-func buildPathPlan(ctx context.Context, client *http.Client, cfg Config, rs RuleSet, base *url.URL) []pathPlan {
+// discoveryInfo carries what the discovery sources learned about a target besides paths.
+type discoveryInfo struct {
+	crawl     *discover.CrawlStats
+	robots    *discover.RobotsFile
+	sitemap   *SitemapReport
+	telemetry DiscoveryReport
+}
+
+func buildPathPlan(ctx context.Context, client *http.Client, cfg Config, rs RuleSet, base *url.URL) ([]pathPlan, discoveryInfo) {
+	var info discoveryInfo
 	seen := make(map[string]pathPlan, len(rs.SensitivePathRules))
 
-	add := func(p string, src DiscoverySource, isSensitive bool, critical bool) {
-		n, ok := normalizePath(p)
+	addPlan := func(pp pathPlan) {
+		n, ok := normalizePath(pp.Path)
 		if !ok {
 			return
 		}
 		if len(n) > 2048 {
 			return
 		}
-		if prev, exists := seen[n]; exists {
-			seen[n] = mergePlan(prev, pathPlan{Path: n, IsSensitive: prev.IsSensitive || isSensitive, Critical: prev.Critical || critical, Source: mergeSource(prev.Source, src)})
+		pp.Path = n
+		if len(pp.Sources) == 0 {
+			pp.Sources = []DiscoverySource{pp.Source}
+		}
+		key := planKey(n)
+		if prev, exists := seen[key]; exists {
+			seen[key] = mergePlan(prev, pp)
 			return
 		}
-		seen[n] = pathPlan{Path: n, IsSensitive: isSensitive, Critical: critical, Source: src}
+		seen[key] = pp
+	}
+	add := func(p string, src DiscoverySource, isSensitive bool, critical bool) {
+		addPlan(pathPlan{Path: p, IsSensitive: isSensitive, Critical: critical, Source: src})
 	}
 
 	for _, r := range rs.SensitivePathRules {
-		add(r.Path, SourceDictionary, true, r.Critical)
+		add(r.Path, SourceDictionary, !contentValidated(r.Category), r.Critical)
+	}
+
+	if cfg.EnableErrorProbes {
+		for _, p := range errorProbePaths() {
+			add(p, SourceErrorProbe, false, false)
+		}
 	}
 
+	tel := &info.telemetry
 	var robotSitemaps []string
 	if cfg.EnableRobots {
-		paths, sitemaps, _ := discover.FetchRobots(ctx, client, base, cfg.UserAgent, cfg.Timeout, 1<<20)
-		robotSitemaps = sitemaps
-		for _, p := range paths {
-			add(p, SourceRobots, false, false)
+		robots, err := discover.FetchRobots(ctx, client, base, cfg.UserAgent, cfg.Timeout, 1<<20)
+		var se *discover.HTTPStatusError
+		switch {
+		case err == nil:
+			tel.RobotsStatus = http.StatusOK
+		case errors.As(err, &se):
+			tel.RobotsStatus = se.StatusCode
+			if se.StatusCode != http.StatusNotFound {
+				tel.Errors = append(tel.Errors, "robots: "+err.Error())
+			}
+		default:
+			tel.Errors = append(tel.Errors, "robots: "+err.Error())
+		}
+		if robots != nil {
+			info.robots = robots
+			robotSitemaps = robots.Sitemaps
+			robotsURL := resolvePath(base, "/robots.txt")
+			// Wildcard rules are expanded to concrete paths; raw patterns are never requested.
+			for _, p := range robots.CandidatePaths() {
+				addPlan(pathPlan{Path: p, Source: SourceRobots, Referrer: robotsURL})
+			}
 		}
 	}
 
@@ -231,14 +443,20 @@ func buildPathPlan(ctx context.Context, client *http.Client, cfg Config, rs Rule
 		for _, s := range robotSitemaps {
 			sitemapSeeds = append(sitemapSeeds, s)
 		}
-		urls, _ := discover.FetchSitemaps(ctx, client, base, sitemapSeeds, cfg.UserAgent, cfg.Timeout, 2<<20, 50)
-		for _, u := range urls {
-			if p, ok := normalizeURLToSameOriginPath(base, u); ok {
-				add(p, SourceSitemap, false, false)
+		entries, stats := discover.FetchSitemaps(ctx, client, base, sitemapSeeds, cfg.UserAgent, cfg.Timeout, 2<<20, 50)
+		tel.SitemapsFetched, tel.SitemapsFailed = stats.Fetched, stats.Failed
+		for _, e := range stats.Errors {
+			tel.Errors = append(tel.Errors, "sitemap: "+e)
+		}
+		info.sitemap = analyzeSitemaps(entries, base, rs)
+		for _, e := range entries {
+			if p, ok := normalizeURLToSameOriginPath(base, e.URL); ok {
+				addPlan(pathPlan{Path: p, Source: SourceSitemap, LastMod: e.LastMod, Referrer: e.Sitemap})
 			}
 		}
 	}
 
+	var crawled []string
 	if cfg.EnableCrawl {
 		depth := cfg.CrawlDepth
 		if depth <= 0 {
@@ -248,11 +466,58 @@ func buildPathPlan(ctx context.Context, client *http.Client, cfg Config, rs Rule
 		if limit <= 0 {
 			limit = 20
 		}
-		urls, _ := discover.CrawlSameOrigin(ctx, client, base, cfg.UserAgent, cfg.Timeout, depth, limit, 256<<10)
-		for _, u := range urls {
-			if p, ok := normalizeURLToSameOriginPath(base, u); ok {
-				add(p, SourceCrawler, false, false)
+		links, stats := discover.CrawlSameOrigin(ctx, client, base, discover.CrawlOptions{
+			UserAgent:     cfg.UserAgent,
+			Timeout:       cfg.Timeout,
+			MaxDepth:      depth,
+			MaxPages:      limit,
+			MaxBytes:      256 << 10,
+			Workers:       cfg.CrawlWorkers,
+			RespectRobots: cfg.CrawlRespectRobots,
+			Include:       cfg.CrawlInclude,
+			Exclude:       cfg.CrawlExclude,
+		})
+		info.crawl = &stats
+		tel.PagesCrawled = stats.Fetched
+		for _, e := range stats.ErrorMessages {
+			tel.Errors = append(tel.Errors, "crawl: "+e)
+		}
+		for _, l := range links {
+			crawled = append(crawled, l.URL)
+			if p, ok := normalizeURLToSameOriginPath(base, l.URL); ok {
+				addPlan(pathPlan{Path: p, Source: SourceCrawler, Referrer: l.Referrer})
+			}
+		}
+	}
+
+	if cfg.EnableJSEndpoints {
+		// Without a crawl only the start page and the scripts it loads are mined.
+		pages := crawled
+		if len(pages) == 0 {
+			pages = []string{base.String()}
+		}
+		refs, stats := discover.FetchEndpoints(ctx, client, base, pages, cfg.UserAgent, cfg.Timeout, 2<<20, 50)
+		for _, e := range stats.Errors {
+			tel.Errors = append(tel.Errors, "js: "+e)
+		}
+		maxPaths := cfg.JSMaxPaths
+		if maxPaths <= 0 {
+			maxPaths = 200
+		}
+		for _, r := range refs {
+			p, ok := normalizeURLToSameOriginPath(base, r.URL)
+			if !ok || p == "/" {
+				continue
+			}
+			// Paths already planned by another source only gain a source and do not count.
+			if _, planned := seen[planKey(p)]; !planned {
+				if tel.JSPaths >= maxPaths {
+					tel.JSPathsDropped++
+					continue
+				}
+				tel.JSPaths++
 			}
+			addPlan(pathPlan{Path: p, Source: SourceJS, Referrer: r.Referrer})
 		}
 	}
 
@@ -264,11 +529,9 @@ func buildPathPlan(ctx context.Context, client *http.Client, cfg Config, rs Rule
 
 	out := make([]pathPlan, 0, len(paths))
 	for _, p := range paths {
-		pp := seen[p]
-		pp.Path = p
-		out = append(out, pp)
+		out = append(out, seen[p])
 	}
-	return out
+	return out, info
 }
 
 func mergeSource(prev, next DiscoverySource) DiscoverySource {
@@ -281,11 +544,32 @@ func mergeSource(prev, next DiscoverySource) DiscoverySource {
 	return prev
 }
 
+func containsSource(list []DiscoverySource, s DiscoverySource) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func mergePlan(prev, next pathPlan) pathPlan {
 	out := prev
 	out.IsSensitive = prev.IsSensitive || next.IsSensitive
 	out.Critical = prev.Critical || next.Critical
 	out.Source = mergeSource(prev.Source, next.Source)
+	if out.LastMod == "" {
+		out.LastMod = next.LastMod
+	}
+	if out.Referrer == "" {
+		out.Referrer = next.Referrer
+	}
+	out.Sources = append([]DiscoverySource(nil), prev.Sources...)
+	for _, s := range next.Sources {
+		if !containsSource(out.Sources, s) {
+			out.Sources = append(out.Sources, s)
+		}
+	}
 	return out
 }
 
@@ -0,0 +1,28 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBuildPathPlanJSMaxPaths(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<script>fetch("/api/a"); fetch("/api/b"); fetch("/api/c"); fetch("/admin"); fetch("/download?file=x")</script>`))
+	}))
+	defer srv.Close()
+	base, _ := url.Parse(srv.URL + "/")
+
+	rs := RuleSet{SensitivePathRules: []SensitivePathRule{{Path: "/admin"}}}
+	cfg := Config{Timeout: 5 * time.Second, EnableJSEndpoints: true, JSMaxPaths: 2}
+	plan, info := buildPathPlan(context.Background(), srv.Client(), cfg, rs, base)
+
+	// /admin was already planned, so it neither counts against the cap nor is dropped.
+	if len(plan) != 3 || info.telemetry.JSPaths != 2 || info.telemetry.JSPathsDropped != 2 {
+		t.Errorf("plan = %+v, telemetry = %+v, want 2 paths added and 2 dropped", plan, info.telemetry)
+	}
+}
@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"html"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// ServerStatus holds the facts extracted from an Apache mod_status page.
+type ServerStatus struct {
+	ServerVersion   string   `json:"server_version,omitempty"`
+	Clients         []string `json:"clients,omitempty"`
+	InternalClients int      `json:"internal_clients,omitempty"`
+	Requests        []string `json:"requests,omitempty"`
+	VHosts          []string `json:"vhosts,omitempty"`
+}
+
+const (
+	maxServerStatusBytes = 1 << 20
+	maxServerStatusItems = 100
+)
+
+var (
+	serverStatusVersionRe = regexp.MustCompile(`(?im)(?:<dt>\s*Server Version:\s*|^ServerVersion:\s*)([^<\r\n]+)`)
+	serverStatusRowRe     = regexp.MustCompile(`(?is)<tr[^>]*>(.*?)</tr>`)
+	serverStatusCellRe    = regexp.MustCompile(`(?is)<t[dh][^>]*>(.*?)</t[dh]>`)
+	serverStatusTagRe     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+func isServerStatus(p, snippet string) bool {
+	return strings.Contains(snippet, "Apache Server Status") || (strings.HasSuffix(p, "/server-status") && strings.Contains(snippet, "ServerVersion:"))
+}
+
+// parseServerStatus locates the worker table columns from its header row, as they vary by version.
+func parseServerStatus(page string) *ServerStatus {
+	st := &ServerStatus{}
+	if m := serverStatusVersionRe.FindStringSubmatch(page); m != nil {
+		st.ServerVersion = strings.TrimSpace(html.UnescapeString(m[1]))
+	}
+
+	clientCol, vhostCol, reqCol := -1, -1, -1
+	clients := make(map[string]struct{})
+	requests := make(map[string]struct{})
+	vhosts := make(map[string]struct{})
+
+	for _, row := range serverStatusRowRe.FindAllStringSubmatch(page, -1) {
+		cells := serverStatusCellRe.FindAllStringSubmatch(row[1], -1)
+		vals := make([]string, len(cells))
+		for i, c := range cells {
+			vals[i] = strings.TrimSpace(html.UnescapeString(serverStatusTagRe.ReplaceAllString(c[1], "")))
+		}
+		if clientCol < 0 {
+			for i, v := range vals {
+				switch v {
+				case "Client":
+					clientCol = i
+				case "VHost":
+					vhostCol = i
+				case "Request":
+					reqCol = i
+				}
+			}
+			continue
+		}
+		cell := func(i int) string {
+			if i < 0 || i >= len(vals) {
+				return ""
+			}
+			return vals[i]
+		}
+		if c := cell(clientCol); c != "" && c != "?" && len(clients) < maxServerStatusItems {
+			clients[c] = struct{}{}
+		}
+		if v := cell(vhostCol); v != "" && len(vhosts) < maxServerStatusItems {
+			vhosts[v] = struct{}{}
+		}
+		if r := cell(reqCol); r != "" && r != "NULL" && !strings.HasPrefix(r, "..") && len(requests) < maxServerStatusItems {
+			requests[r] = struct{}{}
+		}
+	}
+
+	st.Clients = sortedKeys(clients)
+	st.Requests = sortedKeys(requests)
+	st.VHosts = sortedKeys(vhosts)
+	for _, c := range st.Clients {
+		if ip := net.ParseIP(c); ip != nil && (ip.IsPrivate() || ip.IsLoopback()) {
+			st.InternalClients++
+		}
+	}
+	return st
+}
+
+func applyServerStatusEvidence(st *ServerStatus, a *Analysis) {
+	a.Interesting = true
+	if st.ServerVersion != "" {
+		a.Reasons = append(a.Reasons, "server-status discloses "+st.ServerVersion)
+	}
+	if severityRank(a.Severity) < severityRank(SeverityMedium) {
+		a.Severity = SeverityMedium
+	}
+	if len(st.Requests) > 0 || len(st.Clients) > 0 {
+		a.Severity = SeverityHigh
+		a.Reasons = append(a.Reasons, "server-status leaks client addresses and requested URLs")
+	}
+	if st.InternalClients > 0 {
+		a.Reasons = append(a.Reasons, "server-status shows internal client addresses")
+	}
+}
@@ -0,0 +1,184 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Jason-0902/wdf/internal/discover"
+)
+
+// SitemapReport summarises the sitemaps of a target; Flagged includes cross-origin URLs.
+type SitemapReport struct {
+	Files   []string      `json:"files"`
+	URLs    int           `json:"urls"`
+	Flagged []SitemapFlag `json:"flagged,omitempty"`
+}
+
+// SitemapFlag is a sitemap URL that should probably not be advertised to search engines.
+type SitemapFlag struct {
+	URL      string   `json:"url"`
+	Sitemap  string   `json:"sitemap"`
+	LastMod  string   `json:"lastmod,omitempty"`
+	Kind     string   `json:"kind"`
+	Severity Severity `json:"severity"`
+	Reasons  []string `json:"reasons"`
+}
+
+const maxSitemapFlags = 500
+
+var (
+	// Dumps, keys and backups are high; other files that rarely belong in a sitemap are medium.
+	sitemapHighExts   = map[string]bool{".sql": true, ".bak": true, ".env": true, ".pem": true, ".key": true, ".db": true, ".sqlite": true, ".dump": true}
+	sitemapMediumExts = map[string]bool{".zip": true, ".tar": true, ".gz": true, ".tgz": true, ".7z": true, ".rar": true, ".old": true, ".log": true, ".conf": true, ".cfg": true, ".ini": true, ".swp": true, ".backup": true}
+	// Documents are only flagged inside internal-looking directories.
+	sitemapDocExts      = map[string]bool{".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".csv": true, ".ppt": true, ".pptx": true}
+	sitemapInternalDirs = map[string]bool{"internal": true, "private": true, "confidential": true, "admin": true, "backup": true, "backups": true, "hr": true, "finance": true, "staff": true, "intranet": true, "secret": true}
+	stagingHostWords    = []string{"staging", "stage", "dev", "test", "uat", "qa", "preprod", "internal", "local"}
+)
+
+// analyzeSitemaps checks every sitemap entry, not only the ones that end up being scanned.
+func analyzeSitemaps(entries []discover.SitemapEntry, base *url.URL, rs RuleSet) *SitemapReport {
+	if len(entries) == 0 {
+		return nil
+	}
+	rep := &SitemapReport{URLs: len(entries)}
+	files := make(map[string]struct{})
+	for _, e := range entries {
+		if _, ok := files[e.Sitemap]; !ok {
+			files[e.Sitemap] = struct{}{}
+			rep.Files = append(rep.Files, e.Sitemap)
+		}
+		if len(rep.Flagged) >= maxSitemapFlags {
+			continue
+		}
+		if f := checkSitemapEntry(e, base, rs); f != nil {
+			rep.Flagged = append(rep.Flagged, *f)
+		}
+	}
+	return rep
+}
+
+func checkSitemapEntry(e discover.SitemapEntry, base *url.URL, rs RuleSet) *SitemapFlag {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return nil
+	}
+	f := &SitemapFlag{URL: e.URL, Sitemap: e.Sitemap, LastMod: e.LastMod, Kind: e.Kind, Severity: SeverityLow}
+	raise := func(s Severity, reason string) {
+		if severityRank(s) > severityRank(f.Severity) {
+			f.Severity = s
+		}
+		f.Reasons = append(f.Reasons, reason)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if u.Host != "" && !strings.EqualFold(u.Host, base.Host) {
+		if isStagingHost(host) {
+			raise(SeverityMedium, "non-production hostname advertised: "+host)
+		} else {
+			raise(SeverityLow, "cross-origin URL: "+host)
+		}
+	}
+	if base.Scheme == "https" && u.Scheme == "http" {
+		raise(SeverityLow, "http:// URL on an https site")
+	}
+
+	lp := strings.ToLower(u.Path)
+	for _, r := range rs.SensitivePathRules {
+		if !strings.EqualFold(strings.TrimSuffix(r.Path, "/"), strings.TrimSuffix(u.Path, "/")) {
+			continue
+		}
+		reason := "matches sensitive path " + r.Path
+		if r.Category != "" {
+			reason += " (" + r.Category + ")"
+		}
+		if r.Critical {
+			raise(SeverityHigh, reason)
+		} else {
+			raise(SeverityMedium, reason)
+		}
+		break
+	}
+
+	ext := path.Ext(lp)
+	switch {
+	case sitemapHighExts[ext]:
+		raise(SeverityHigh, "sensitive file type "+ext)
+	case sitemapMediumExts[ext]:
+		raise(SeverityMedium, "sensitive file type "+ext)
+	case sitemapDocExts[ext]:
+		for _, seg := range strings.Split(path.Dir(lp), "/") {
+			if sitemapInternalDirs[seg] {
+				raise(SeverityMedium, "document in internal directory /"+seg+"/")
+				break
+			}
+		}
+	}
+
+	if len(f.Reasons) == 0 {
+		return nil
+	}
+	return f
+}
+
+// isStagingHost matches labels such as staging, dev2 or app-uat.
+func isStagingHost(host string) bool {
+	for _, label := range strings.FieldsFunc(host, func(r rune) bool { return r == '.' || r == '-' }) {
+		for _, w := range stagingHostWords {
+			if strings.HasPrefix(label, w) && strings.Trim(label[len(w):], "0123456789") == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inspectSitemapFlags attaches medium and high flags to the matching same-origin results.
+func inspectSitemapFlags(tr *TargetResult) {
+	if tr.Sitemap == nil || len(tr.Sitemap.Flagged) == 0 {
+		return
+	}
+	base, err := url.Parse(tr.Normalized)
+	if err != nil {
+		return
+	}
+	byKey := make(map[string]*RequestResult, len(tr.Results))
+	for i := range tr.Results {
+		byKey[planKey(tr.Results[i].Path)] = &tr.Results[i]
+	}
+	for i := range tr.Sitemap.Flagged {
+		f := &tr.Sitemap.Flagged[i]
+		if severityRank(f.Severity) < severityRank(SeverityMedium) {
+			continue
+		}
+		p, ok := normalizeURLToSameOriginPath(base, f.URL)
+		if !ok {
+			continue
+		}
+		rr := byKey[planKey(p)]
+		if rr == nil {
+			continue
+		}
+		rr.evidence().Sitemap = f
+		applySitemapFlag(f, rr)
+	}
+}
+
+func applySitemapFlag(f *SitemapFlag, rr *RequestResult) {
+	a := &rr.Analysis
+	// A 404 or 410 keeps the reason but is not a finding; only served content is.
+	if rr.StatusCode >= 200 && rr.StatusCode < 400 {
+		a.Interesting = true
+		if severityRank(a.Severity) < severityRank(f.Severity) {
+			a.Severity = f.Severity
+		}
+	}
+	for _, r := range f.Reasons {
+		a.Reasons = append(a.Reasons, "advertised in sitemap: "+r)
+	}
+	a.Reasons = dedupeStrings(a.Reasons)
+	if rr.RecommendedFix == "" {
+		rr.RecommendedFix = recommendedFix(rr.Path, SourceSitemap, *a, rr.flags, rr.sensitive)
+	}
+}
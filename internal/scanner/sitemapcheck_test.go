@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Jason-0902/wdf/internal/discover"
+)
+
+func TestInspectSitemapFlags(t *testing.T) {
+	base, _ := url.Parse("https://example.com")
+	var flagged []SitemapFlag
+	for _, raw := range []string{"http://example.com/about", "https://cdn.example.net/a.js", "https://example.com/backup/site.zip", "https://example.com/old/db.sql"} {
+		if f := checkSitemapEntry(discover.SitemapEntry{URL: raw, Sitemap: "https://example.com/sitemap.xml"}, base, RuleSet{}); f != nil {
+			flagged = append(flagged, *f)
+		}
+	}
+	if len(flagged) != 4 {
+		t.Fatalf("flagged %d entries, want 4: %+v", len(flagged), flagged)
+	}
+
+	tr := &TargetResult{
+		Normalized: "https://example.com",
+		Sitemap:    &SitemapReport{Flagged: flagged},
+		Results: []RequestResult{
+			{Path: "/about", StatusCode: 200},
+			{Path: "/backup/site.zip", StatusCode: 200},
+			{Path: "/old/db.sql", StatusCode: 404},
+		},
+	}
+	inspectSitemapFlags(tr)
+
+	if rr := tr.Results[0]; rr.Analysis.Interesting || rr.Evidence != nil {
+		t.Errorf("scheme-only flag applied to result: %+v", rr)
+	}
+	if rr := tr.Results[1]; !rr.Analysis.Interesting || rr.Analysis.Severity != SeverityMedium {
+		t.Errorf("medium flag not applied: %+v", rr.Analysis)
+	}
+	if rr := tr.Results[2]; rr.Analysis.Interesting || rr.Analysis.Severity != "" || len(rr.Analysis.Reasons) != 1 || rr.Evidence == nil {
+		t.Errorf("flag on a 404 should keep its reason without marking the result interesting: %+v", rr.Analysis)
+	}
+	if len(tr.Sitemap.Flagged) != 4 {
+		t.Errorf("report lost flags: %+v", tr.Sitemap.Flagged)
+	}
+}